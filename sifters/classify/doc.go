@@ -0,0 +1,2 @@
+// Provides an event-sifter based on a local, trainable spam classifier.
+package classify
@@ -0,0 +1,254 @@
+package classify
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Label is a label of an event for training classifiers.
+type Label int
+
+const (
+	// The event is not spam.
+	Ham Label = iota + 1
+
+	// The event is spam.
+	Spam
+)
+
+func (l Label) String() string {
+	switch l {
+	case Ham:
+		return "ham"
+	case Spam:
+		return "spam"
+	default:
+		return "unknown"
+	}
+}
+
+func (l Label) MarshalJSON() ([]byte, error) {
+	if l != Ham && l != Spam {
+		return nil, fmt.Errorf("unknown label: %d", l)
+	}
+	return json.Marshal(l.String())
+}
+
+func (l *Label) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "ham":
+		*l = Ham
+	case "spam":
+		*l = Spam
+	default:
+		return fmt.Errorf("unknown label: %q", s)
+	}
+	return nil
+}
+
+// LabeledEvent is an event with a label, used for training classifiers.
+//
+// In JSONL files for training, each line should be a LabeledEvent encoded as JSON, like:
+//
+//	{"label": "spam", "event": {"id": "...", "pubkey": "...", "content": "...", ...}}
+type LabeledEvent struct {
+	Label Label        `json:"label"`
+	Event *nostr.Event `json:"event"`
+}
+
+// Model is a naive Bayes spam classifier, which estimates how likely an event is spam from tokens (words) in the content.
+//
+// A Model can be trained offline from labeled events (see [Model.TrainJSONL]), saved to a file by [Model.Save] and loaded by [LoadModel].
+// It can also be trained incrementally while it is in use (e.g. from decisions of moderators) by [Model.Train],
+// since all methods of Model are safe for concurrent use.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [NewModel] or [LoadModel] function to construct an instance of Model.
+type Model struct {
+	mu     sync.RWMutex
+	docs   [2]int            // number of trained events per label (index 0: ham, 1: spam)
+	tokens map[string][2]int // number of trained events containing the token per label
+}
+
+// NewModel creates an empty [Model].
+func NewModel() *Model {
+	return &Model{
+		tokens: make(map[string][2]int),
+	}
+}
+
+func labelIndex(l Label) (int, error) {
+	switch l {
+	case Ham:
+		return 0, nil
+	case Spam:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unknown label: %d", l)
+	}
+}
+
+// Train trains the model with the event labeled as the given label.
+func (m *Model) Train(ev *nostr.Event, label Label) error {
+	return m.update(ev, label, 1)
+}
+
+// Untrain reverts the effect of training the model with the event labeled as the given label.
+// It is useful for correcting mistakes in training (e.g. when moderators change their decision).
+func (m *Model) Untrain(ev *nostr.Event, label Label) error {
+	return m.update(ev, label, -1)
+}
+
+func (m *Model) update(ev *nostr.Event, label Label, delta int) error {
+	li, err := labelIndex(label)
+	if err != nil {
+		return err
+	}
+	tokens := tokenize(ev.Content)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.docs[li] = max(0, m.docs[li]+delta)
+	for t := range tokens {
+		c := m.tokens[t]
+		c[li] = max(0, c[li]+delta)
+		if c == [2]int{} {
+			delete(m.tokens, t)
+			continue
+		}
+		m.tokens[t] = c
+	}
+	return nil
+}
+
+// TrainJSONL trains the model with labeled events read from r, in the form of JSONL (see [LabeledEvent] for the format).
+// It returns the number of events trained.
+func (m *Model) TrainJSONL(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	n := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var le LabeledEvent
+		if err := json.Unmarshal(scanner.Bytes(), &le); err != nil {
+			return n, fmt.Errorf("failed to parse labeled event at line %d: %w", line, err)
+		}
+		if le.Event == nil {
+			return n, fmt.Errorf("labeled event at line %d has no event", line)
+		}
+		if err := m.Train(le.Event, le.Label); err != nil {
+			return n, fmt.Errorf("failed to train with labeled event at line %d: %w", line, err)
+		}
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return n, fmt.Errorf("failed to read labeled events: %w", err)
+	}
+	return n, nil
+}
+
+// SpamScore returns the probability that the event is spam estimated by the model, between 0 and 1.
+//
+// If the model hasn't been trained with both spam and ham events, it returns 0.5.
+func (m *Model) SpamScore(ev *nostr.Event) float64 {
+	score, _ := m.spamScore(ev)
+	return score
+}
+
+// spamScore returns the spam score of the event. ok is false if the model hasn't been trained with both spam and ham events.
+func (m *Model) spamScore(ev *nostr.Event) (score float64, ok bool) {
+	tokens := tokenize(ev.Content)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.docs[0] == 0 || m.docs[1] == 0 {
+		return 0.5, false
+	}
+
+	// log(P(spam|tokens) / P(ham|tokens)), with Laplace smoothing
+	logOdds := math.Log(float64(m.docs[1])) - math.Log(float64(m.docs[0]))
+	for t := range tokens {
+		c, ok := m.tokens[t]
+		if !ok {
+			// tokens never seen in training carry no information
+			continue
+		}
+		pSpam := float64(c[1]+1) / float64(m.docs[1]+2)
+		pHam := float64(c[0]+1) / float64(m.docs[0]+2)
+		logOdds += math.Log(pSpam) - math.Log(pHam)
+	}
+	return 1 / (1 + math.Exp(-logOdds)), true
+}
+
+// serialized form of Model
+type modelFile struct {
+	Version int               `json:"version"`
+	Ham     int               `json:"ham"`
+	Spam    int               `json:"spam"`
+	Tokens  map[string][2]int `json:"tokens"` // token -> [ham count, spam count]
+}
+
+const modelFileVersion = 1
+
+// Save writes the model to the file at the given path.
+func (m *Model) Save(path string) error {
+	m.mu.RLock()
+	b, err := json.Marshal(modelFile{
+		Version: modelFileVersion,
+		Ham:     m.docs[0],
+		Spam:    m.docs[1],
+		Tokens:  m.tokens,
+	})
+	m.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(path, b); err != nil {
+		return fmt.Errorf("failed to save model: %w", err)
+	}
+	return nil
+}
+
+// LoadModel loads a [Model] from the file at the given path, which is saved by [Model.Save].
+func LoadModel(path string) (*Model, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model file: %w", err)
+	}
+	var mf modelFile
+	if err := json.Unmarshal(b, &mf); err != nil {
+		return nil, fmt.Errorf("failed to parse model file: %w", err)
+	}
+	if mf.Version != modelFileVersion {
+		return nil, fmt.Errorf("unsupported model file version: %d", mf.Version)
+	}
+	if mf.Ham < 0 || mf.Spam < 0 {
+		return nil, errors.New("model file is corrupted")
+	}
+
+	m := NewModel()
+	m.docs = [2]int{mf.Ham, mf.Spam}
+	if mf.Tokens != nil {
+		m.tokens = mf.Tokens
+	}
+	return m, nil
+}
@@ -0,0 +1,142 @@
+package classify
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	spamContents = []string{
+		"free bitcoin giveaway! click the link to claim now",
+		"claim your free sats now, limited giveaway",
+		"airdrop! free tokens, click to claim",
+		"get rich quick, click the link now",
+	}
+	hamContents = []string{
+		"good morning nostr, the weather is nice today",
+		"i wrote a blog post about relays and clients",
+		"what are you reading this week?",
+		"the meetup today was fun, thanks everyone",
+	}
+)
+
+func trainingJSONL(t *testing.T) string {
+	t.Helper()
+
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	for _, c := range spamContents {
+		if err := enc.Encode(LabeledEvent{Label: Spam, Event: &nostr.Event{Content: c}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range hamContents {
+		if err := enc.Encode(LabeledEvent{Label: Ham, Event: &nostr.Event{Content: c}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sb.String()
+}
+
+func trainedModel(t *testing.T) *Model {
+	t.Helper()
+
+	m := NewModel()
+	n, err := m.TrainJSONL(strings.NewReader(trainingJSONL(t)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != len(spamContents)+len(hamContents) {
+		t.Fatalf("unexpected number of trained events: %d", n)
+	}
+	return m
+}
+
+func TestModel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("scores spam-like events high and ham-like events low", func(t *testing.T) {
+		m := trainedModel(t)
+
+		if s := m.SpamScore(&nostr.Event{Content: "FREE giveaway, click now!"}); s < 0.9 {
+			t.Errorf("unexpected score for spam-like event: %f", s)
+		}
+		if s := m.SpamScore(&nostr.Event{Content: "good morning, what are you reading today?"}); s > 0.1 {
+			t.Errorf("unexpected score for ham-like event: %f", s)
+		}
+	})
+
+	t.Run("returns 0.5 if the model is not trained", func(t *testing.T) {
+		m := NewModel()
+		if err := m.Train(&nostr.Event{Content: "free giveaway"}, Spam); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s := m.SpamScore(&nostr.Event{Content: "free giveaway"}); s != 0.5 {
+			t.Errorf("unexpected score: %f", s)
+		}
+	})
+
+	t.Run("can be trained incrementally", func(t *testing.T) {
+		m := trainedModel(t)
+		ev := &nostr.Event{Content: "join our discord server for exclusive alpha"}
+
+		before := m.SpamScore(ev)
+		for i := 0; i < 3; i++ {
+			if err := m.Train(ev, Spam); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		after := m.SpamScore(ev)
+		if after <= before || after < 0.9 {
+			t.Errorf("unexpected score after training: before=%f, after=%f", before, after)
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := m.Untrain(ev, Spam); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if s := m.SpamScore(ev); math.Abs(s-before) > 1e-9 {
+			t.Errorf("unexpected score after untraining: %f (before training: %f)", s, before)
+		}
+	})
+
+	t.Run("fails to train with invalid JSONL", func(t *testing.T) {
+		inputs := []string{
+			`{"label": "spam", "event": {"content": "free sats"}}` + "\n" + `{"label": "eggs", "event": {"content": "free sats"}}`,
+			`{"label": "ham"}`,
+			`not a json`,
+		}
+		for _, in := range inputs {
+			if _, err := NewModel().TrainJSONL(strings.NewReader(in)); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		}
+	})
+
+	t.Run("can be saved and loaded", func(t *testing.T) {
+		m := trainedModel(t)
+		path := filepath.Join(t.TempDir(), "model.json")
+
+		if err := m.Save(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		loaded, err := LoadModel(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, c := range append(spamContents, hamContents...) {
+			ev := &nostr.Event{Content: c}
+			// scores may differ slightly since the order of summation is not deterministic
+			if math.Abs(m.SpamScore(ev)-loaded.SpamScore(ev)) > 1e-9 {
+				t.Errorf("score mismatch for %q: %f vs %f", c, m.SpamScore(ev), loaded.SpamScore(ev))
+			}
+		}
+	})
+}
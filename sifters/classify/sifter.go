@@ -0,0 +1,80 @@
+package classify
+
+import (
+	"fmt"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+// SifterUnit is a spam classifier based event-sifter.
+//
+// SifterUnit rejects with message: "blocked: event is classified as spam" by default.
+// If you want to customize rejection behavior,
+// call [SifterUnit.RejectWithMsg], [SifterUnit.RejectWithMsgFromInput] or [SifterUnit.ShadowReject] methods on it.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+type SifterUnit struct {
+	model     *Model
+	threshold float64
+	onScore   func(*strfrui.Input, float64)
+	reject    internal.RejectionFn
+}
+
+func (s *SifterUnit) Sift(input *strfrui.Input) (*strfrui.Result, error) {
+	score, ok := s.model.spamScore(input.Event)
+	if !ok {
+		// an untrained model can't tell spam from ham
+		return input.Accept()
+	}
+	if s.onScore != nil {
+		s.onScore(input, score)
+	}
+	if score >= s.threshold {
+		return s.reject(input), nil
+	}
+	return input.Accept()
+}
+
+// OnScore registers a callback that is called with the input and its spam score every time the sifter classifies an input.
+// It is useful for logging scores to tune the threshold, or for collecting candidates for moderators to label.
+func (s *SifterUnit) OnScore(f func(input *strfrui.Input, score float64)) *SifterUnit {
+	s.onScore = f
+	return s
+}
+
+// ShadowReject sets the sifter's rejection behavior to "shadow-reject",
+// which pretend to accept the input but actually reject it.
+func (s *SifterUnit) ShadowReject() *SifterUnit {
+	s.reject = internal.ShadowReject
+	return s
+}
+
+// RejectWithMsg makes the sifter reject the input with the given message.
+func (s *SifterUnit) RejectWithMsg(msg string) *SifterUnit {
+	s.reject = internal.RejectWithMsg(msg)
+	return s
+}
+
+// RejectWithMsgFromInput makes the sifter reject the input with the message derived from the input by the given function.
+func (s *SifterUnit) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string) *SifterUnit {
+	s.reject = internal.RejectWithMsgFromInput(getMsg)
+	return s
+}
+
+// SpamScoreBelow creates an event-sifter that rejects events whose spam score estimated by the model is higher than or equal to the threshold.
+//
+// The threshold must be in the range of (0.5, 1]. Otherwise, it panics.
+// Events are always accepted while the model hasn't been trained with both spam and ham events.
+//
+// The model can be trained further while the sifter is in use (see [Model.Train]), and the sifter uses the up-to-date model.
+func SpamScoreBelow(model *Model, threshold float64) *SifterUnit {
+	if !(threshold > 0.5 && threshold <= 1) {
+		panic(fmt.Sprintf("classify.SpamScoreBelow: threshold must be in the range of (0.5, 1], but got %v", threshold))
+	}
+	return &SifterUnit{
+		model:     model,
+		threshold: threshold,
+		reject:    internal.RejectWithMsg("blocked: event is classified as spam"),
+	}
+}
@@ -0,0 +1,92 @@
+package classify
+
+import (
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSpamScoreBelow(t *testing.T) {
+	t.Parallel()
+
+	m := trainedModel(t)
+	var scores []float64
+	s := SpamScoreBelow(m, 0.9).OnScore(func(_ *strfrui.Input, score float64) {
+		scores = append(scores, score)
+	})
+
+	tests := []struct {
+		content string
+		want    strfrui.Action
+	}{
+		{"FREE giveaway, click now!", strfrui.ActionReject},
+		{"good morning, what are you reading today?", strfrui.ActionAccept},
+	}
+	for _, tt := range tests {
+		res, err := s.Sift(&strfrui.Input{Event: &nostr.Event{Content: tt.content}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != tt.want {
+			t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+		}
+		if tt.want == strfrui.ActionReject && res.Msg != "blocked: event is classified as spam" {
+			t.Fatalf("unexpected message: %q", res.Msg)
+		}
+	}
+	if len(scores) != len(tests) {
+		t.Fatalf("unexpected number of reported scores: %d", len(scores))
+	}
+
+	// the sifter uses the up-to-date model
+	ev := &nostr.Event{Content: "join our discord server for exclusive alpha"}
+	for i := 0; i < 5; i++ {
+		if err := m.Train(ev, Spam); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	res, err := s.Sift(&strfrui.Input{Event: ev})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Action != strfrui.ActionReject {
+		t.Fatalf("unexpected result after training: %+v", res)
+	}
+}
+
+func TestSpamScoreBelow_UntrainedModel(t *testing.T) {
+	t.Parallel()
+
+	m := NewModel()
+	// trained with spam events only
+	if err := m.Train(&nostr.Event{Content: "FREE giveaway, click now!"}, Spam); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := SpamScoreBelow(m, 0.51)
+
+	for _, content := range []string{"FREE giveaway, click now!", "good morning"} {
+		res, err := s.Sift(&strfrui.Input{Event: &nostr.Event{Content: content}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionAccept {
+			t.Fatalf("unexpected result for %q: %+v", content, res)
+		}
+	}
+}
+
+func TestSpamScoreBelow_InvalidThreshold(t *testing.T) {
+	t.Parallel()
+
+	for _, threshold := range []float64{0, 0.3, 0.5, 1.1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SpamScoreBelow didn't panic with threshold %v", threshold)
+				}
+			}()
+			SpamScoreBelow(NewModel(), threshold)
+		}()
+	}
+}
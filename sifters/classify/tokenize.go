@@ -0,0 +1,70 @@
+package classify
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// max length of tokens in bytes. longer tokens (e.g. long hex strings) are ignored
+const maxTokenLen = 40
+
+// tokenize splits the text into a set of tokens used as features of the classifier.
+//
+// The text is normalized by NFKC and lowercased, then split into words at non-letter/digit characters.
+// Texts in scripts that don't separate words with spaces (Chinese and Japanese) are split into character bigrams instead.
+func tokenize(text string) map[string]struct{} {
+	text = strings.ToLower(norm.NFKC.String(text))
+
+	tokens := make(map[string]struct{})
+	addToken := func(t string) {
+		if len(t) <= maxTokenLen {
+			tokens[t] = struct{}{}
+		}
+	}
+
+	var word []rune
+	flushWord := func() {
+		if len(word) > 1 {
+			addToken(string(word))
+		}
+		word = word[:0]
+	}
+
+	var cjk []rune
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			addToken(string(cjk))
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				addToken(string(cjk[i : i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return r == 'ー' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
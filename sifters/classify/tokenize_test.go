@@ -0,0 +1,31 @@
+package classify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World! hello", []string{"hello", "world"}},
+		{"ＦＲＥＥ sats: https://example.com", []string{"free", "sats", "https", "example", "com"}},
+		{"a b 1 12", []string{"12"}},
+		{"無料ビットコイン", []string{"無料", "料ビ", "ビッ", "ット", "トコ", "コイ", "イン"}},
+		{"今 nostr", []string{"今", "nostr"}},
+		{"deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef ok", []string{"ok"}},
+	}
+	for _, tt := range tests {
+		want := make(map[string]struct{})
+		for _, w := range tt.want {
+			want[w] = struct{}{}
+		}
+		if got := tokenize(tt.text); !reflect.DeepEqual(got, want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.text, got, want)
+		}
+	}
+}
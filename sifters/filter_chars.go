@@ -0,0 +1,127 @@
+package sifters
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+// CharacterAbuseLimits describes limits on abusive use of special characters in contents, which can break clients or evade filters.
+// Zero values mean "unlimited" (or "allowed" for boolean fields).
+type CharacterAbuseLimits struct {
+	// Max number of combining marks stacked on a single character. Excess ones are typical for "Zalgo" texts.
+	MaxCombiningMarksPerChar int
+
+	// Max ratio of combining marks to all characters in the content, between 0 and 1.
+	// Note that texts in some languages (e.g. Arabic and Hebrew with diacritics) legitimately have many combining marks.
+	MaxCombiningMarkRatio float64
+
+	// Max number of invisible characters, such as zero-width spaces/joiners and bidi control characters.
+	// Variation selectors are not counted, nor are zero-width (non-)joiners between letters or within emoji sequences,
+	// which are legitimately used in some languages (e.g. Persian) and emoji (e.g. 👨‍👩‍👧).
+	MaxInvisibleChars int
+
+	// If true, contents with bidi embedding, override and isolate characters (U+202A-U+202E and U+2066-U+2069) are rejected.
+	// They can reorder the displayed text to disguise what it actually says.
+	// Bidi marks (e.g. U+200F RIGHT-TO-LEFT MARK) are allowed, as they don't reorder texts by themselves.
+	RejectBidiControls bool
+
+	// If true, contents with control characters other than tabs and newlines are rejected.
+	RejectControlChars bool
+}
+
+// DefaultCharacterAbuseLimits returns a [CharacterAbuseLimits] with lenient limits that are unlikely to affect legitimate texts.
+func DefaultCharacterAbuseLimits() CharacterAbuseLimits {
+	return CharacterAbuseLimits{
+		MaxCombiningMarksPerChar: 4,
+		MaxInvisibleChars:        20,
+		RejectBidiControls:       true,
+		RejectControlChars:       true,
+	}
+}
+
+// ContentCharacterAbuse makes an event-sifter that checks if a content of a Nostr event is free from abusive use of special characters:
+// excessive combining marks ("Zalgo" texts), invisible characters, bidi controls and control characters.
+//
+// This sifter rejects events that exceed the given limits with messages prefixed with "invalid:" by default.
+func ContentCharacterAbuse(limits CharacterAbuseLimits) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		if msg := checkCharacterAbuse(input.Event.Content, limits); msg != "" {
+			return rejectWithMsg(msg)
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: content has abusive use of special characters")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+func checkCharacterAbuse(content string, limits CharacterAbuseLimits) string {
+	var (
+		chars     int
+		marks     int
+		marksRun  int
+		invisible int
+	)
+	prev := rune(-1)
+	for i, r := range content {
+		chars++
+
+		if isCombiningMark(r) {
+			marks++
+			marksRun++
+			if limits.MaxCombiningMarksPerChar > 0 && marksRun > limits.MaxCombiningMarksPerChar {
+				return fmt.Sprintf("invalid: content has too many combining marks on a character (max: %d)", limits.MaxCombiningMarksPerChar)
+			}
+		} else {
+			marksRun = 0
+		}
+
+		if limits.RejectBidiControls && isBidiControl(r) {
+			return "invalid: content has bidi control characters"
+		}
+		if limits.RejectControlChars && unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return "invalid: content has control characters"
+		}
+		if isInvisible(r) && !unicode.Is(unicode.Variation_Selector, r) && !isJoinerInContext(r, prev, content[i+utf8.RuneLen(r):]) {
+			invisible++
+			if limits.MaxInvisibleChars > 0 && invisible > limits.MaxInvisibleChars {
+				return fmt.Sprintf("invalid: content has too many invisible characters (max: %d)", limits.MaxInvisibleChars)
+			}
+		}
+		prev = r
+	}
+
+	if limits.MaxCombiningMarkRatio > 0 && chars > 0 && float64(marks)/float64(chars) > limits.MaxCombiningMarkRatio {
+		return "invalid: content has too many combining marks"
+	}
+	return ""
+}
+
+// isCombiningMark checks if r is a combining mark, excluding variation selectors.
+func isCombiningMark(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) && !unicode.Is(unicode.Variation_Selector, r)
+}
+
+// isBidiControl checks if r is a bidi embedding, override or isolate character.
+func isBidiControl(r rune) bool {
+	return ('\u202a' <= r && r <= '\u202e') || ('\u2066' <= r && r <= '\u2069')
+}
+
+// isJoinerInContext checks if r is a zero-width (non-)joiner placed between letters or emoji, where it is meaningful.
+// prev is the character before r (-1 if none), and rest is the content after r.
+func isJoinerInContext(r rune, prev rune, rest string) bool {
+	if r != '\u200c' && r != '\u200d' {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(rest)
+	return prev >= 0 && isJoinable(prev) && rest != "" && isJoinable(next)
+}
+
+// isJoinable checks if r can be joined to adjacent characters by zero-width (non-)joiners:
+// letters, marks (including variation selectors) and symbols (including emoji and skin tone modifiers).
+func isJoinable(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.In(r, unicode.So, unicode.Sk)
+}
@@ -0,0 +1,137 @@
+package sifters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+)
+
+func TestContentCharacterAbuse(t *testing.T) {
+	t.Run("accepts legitimate contents with default limits", func(t *testing.T) {
+		s := ContentCharacterAbuse(DefaultCharacterAbuseLimits())
+
+		cs := []string{
+			"hello,\tworld!\r\n",
+			"café, cafe\u0301",                   // precomposed and decomposed
+			"Tie\u0302\u0301ng Vie\u0323\u0302t", // Vietnamese with stacked diacritics (decomposed)
+			"👨\u200d👩\u200d👧\u200d👦 ❤\ufe0f",     // emoji ZWJ sequence and variation selector
+			"مرحبا \u200fעברית",                  // bidi marks are allowed
+		}
+		for _, c := range cs {
+			res, err := s.Sift(inputWithContent(c))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result for %q: %+v", c, res)
+			}
+		}
+	})
+
+	t.Run("rejects abusive contents", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			limits  CharacterAbuseLimits
+			content string
+			wantMsg string
+		}{
+			{
+				"zalgo",
+				DefaultCharacterAbuseLimits(),
+				"he\u0300\u0301\u0302\u0303\u0304llo",
+				"invalid: content has too many combining marks on a character (max: 4)",
+			},
+			{
+				"density of combining marks",
+				CharacterAbuseLimits{MaxCombiningMarkRatio: 0.3},
+				"a\u0300b\u0301c\u0302",
+				"invalid: content has too many combining marks",
+			},
+			{
+				"invisible characters",
+				CharacterAbuseLimits{MaxInvisibleChars: 3},
+				strings.Repeat("a\u200b", 4),
+				"invalid: content has too many invisible characters (max: 3)",
+			},
+			{
+				"invisible characters: joiners out of context",
+				CharacterAbuseLimits{MaxInvisibleChars: 3},
+				"\u200da \u200c b\u200d! \u200d",
+				"invalid: content has too many invisible characters (max: 3)",
+			},
+			{
+				"bidi override",
+				DefaultCharacterAbuseLimits(),
+				"file\u202egpj.exe",
+				"invalid: content has bidi control characters",
+			},
+			{
+				"bidi embedding",
+				DefaultCharacterAbuseLimits(),
+				"abc\u202bevil\u202c",
+				"invalid: content has bidi control characters",
+			},
+			{
+				"bidi isolate",
+				DefaultCharacterAbuseLimits(),
+				"abc\u2067evil\u2069",
+				"invalid: content has bidi control characters",
+			},
+			{
+				"control characters",
+				DefaultCharacterAbuseLimits(),
+				"hello\x00world",
+				"invalid: content has control characters",
+			},
+			{
+				"C1 control characters",
+				DefaultCharacterAbuseLimits(),
+				"hello\u0085world",
+				"invalid: content has control characters",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res, err := ContentCharacterAbuse(tt.limits).Sift(inputWithContent(tt.content))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+					t.Fatalf("unexpected result: %+v", res)
+				}
+			})
+		}
+	})
+
+	t.Run("doesn't count zero-width joiners between letters or within emoji sequences", func(t *testing.T) {
+		s := ContentCharacterAbuse(DefaultCharacterAbuseLimits())
+
+		cs := []string{
+			strings.Repeat("باورم نمی\u200cشود این رله چقدر سریع است. ", 21), // Persian uses ZWNJ within words
+			strings.Repeat("👨\u200d👩\u200d👧\u200d👦", 7),
+			strings.Repeat("❤\ufe0f\u200d🔥 👩🏽\u200d💻 ", 11), // ZWJ after a variation selector / skin tone modifier
+		}
+		for _, c := range cs {
+			res, err := s.Sift(inputWithContent(c))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result for %q: %+v", c, res)
+			}
+		}
+	})
+
+	t.Run("allows everything with zero limits", func(t *testing.T) {
+		s := ContentCharacterAbuse(CharacterAbuseLimits{})
+
+		res, err := s.Sift(inputWithContent("h\u0300\u0301\u0302\u0303\u0304\u0305\u202e\x00" + strings.Repeat("\u200b", 100)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionAccept {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
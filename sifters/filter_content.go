@@ -3,6 +3,8 @@ package sifters
 import (
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jiftechnify/strfrui"
 )
@@ -24,7 +26,7 @@ func ContentMatcher(matcher func(string) (bool, error), mode Mode) *SifterUnit {
 
 // ContentHasAnyWord makes an event-sifter that checks if a content of a Nostr event has any word in the given list.
 //
-// Note that it performs case-sensitive match.
+// Note that it performs case-sensitive match. If you want more robust matching, use [ContentHasAnyWordWithOptions] instead.
 func ContentHasAnyWord(words []string, mode Mode) *SifterUnit {
 	matchInput := func(i *strfrui.Input) (inputMatchResult, error) {
 		for _, word := range words {
@@ -44,7 +46,7 @@ func ContentHasAnyWord(words []string, mode Mode) *SifterUnit {
 
 // ContentHasAllWords makes an event-sifter that checks if a content of a Nostr event has all words in the given list.
 //
-// Note that it performs case-sensitive match.
+// Note that it performs case-sensitive match. If you want more robust matching, use [ContentHasAllWordsWithOptions] instead.
 func ContentHasAllWords(words []string, mode Mode) *SifterUnit {
 	matchInput := func(i *strfrui.Input) (inputMatchResult, error) {
 		for _, word := range words {
@@ -61,6 +63,60 @@ func ContentHasAllWords(words []string, mode Mode) *SifterUnit {
 	return newSifterUnit(matchInput, mode, defaultRejFn)
 }
 
+// WordMatchOptions describes how [ContentHasAnyWordWithOptions] and [ContentHasAllWordsWithOptions] match words with contents.
+type WordMatchOptions struct {
+	// Normalization applied to both contents and words before matching.
+	Normalization TextNormalization
+
+	// If true, words only match at word boundaries (e.g. "zap" matches "zap me!" but not "zapper").
+	//
+	// Scripts that don't separate words with spaces (Chinese, Japanese, Thai etc.) are taken into account:
+	// any boundary next to a character of those scripts is considered a word boundary,
+	// so words in those scripts are matched even if they are surrounded by other characters.
+	WholeWord bool
+}
+
+// ContentHasAnyWordWithOptions makes an event-sifter that checks if a content of a Nostr event has any word in the given list,
+// with normalization and word boundary rules specified by opts.
+func ContentHasAnyWordWithOptions(words []string, opts WordMatchOptions, mode Mode) *SifterUnit {
+	words = normalizeWords(words, opts.Normalization)
+	matchInput := func(i *strfrui.Input) (inputMatchResult, error) {
+		content := opts.Normalization.normalize(i.Event.Content)
+		for _, word := range words {
+			if containsWord(content, word, opts.WholeWord) {
+				return inputMatch, nil
+			}
+		}
+		return inputMismatch, nil
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: content must have one of keywords to be accepted",
+		"blocked: content has one of forbidden words",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+// ContentHasAllWordsWithOptions makes an event-sifter that checks if a content of a Nostr event has all words in the given list,
+// with normalization and word boundary rules specified by opts.
+func ContentHasAllWordsWithOptions(words []string, opts WordMatchOptions, mode Mode) *SifterUnit {
+	words = normalizeWords(words, opts.Normalization)
+	matchInput := func(i *strfrui.Input) (inputMatchResult, error) {
+		content := opts.Normalization.normalize(i.Event.Content)
+		for _, word := range words {
+			if !containsWord(content, word, opts.WholeWord) {
+				return inputMismatch, nil
+			}
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := rejectWithMsgPerMode(mode,
+		"blocked: content must have all keywords to be accepted",
+		"blocked: content has all of forbidden words",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
 // ContentMatchesAnyRegexp makes an event-sifter that checks if a content of a Nostr event matches any of the given list of regular expressions.
 func ContentMatchesAnyRegexp(regexps []*regexp.Regexp, mode Mode) *SifterUnit {
 	matchInput := func(i *strfrui.Input) (inputMatchResult, error) {
@@ -96,3 +152,62 @@ func ContentMatchesAllRegexps(regexps []*regexp.Regexp, mode Mode) *SifterUnit {
 	)
 	return newSifterUnit(matchInput, mode, defaultRejectFn)
 }
+
+// normalizeWords normalizes each word in the list. Words that become empty after normalization are dropped.
+func normalizeWords(words []string, n TextNormalization) []string {
+	res := make([]string, 0, len(words))
+	for _, w := range words {
+		if nw := n.normalize(w); nw != "" {
+			res = append(res, nw)
+		}
+	}
+	return res
+}
+
+// containsWord checks if text contains word. If wholeWord is true, only occurrences at word boundaries count.
+func containsWord(text, word string, wholeWord bool) bool {
+	if !wholeWord {
+		return strings.Contains(text, word)
+	}
+
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		if isWordBoundary(text, start) && isWordBoundary(text, end) {
+			return true
+		}
+		// search again from the next character
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// isWordBoundary checks if the position i of text is a word boundary.
+func isWordBoundary(text string, i int) bool {
+	if i == 0 || i == len(text) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(text[:i])
+	after, _ := utf8.DecodeRuneInString(text[i:])
+	if !isWordChar(before) || !isWordChar(after) {
+		return true
+	}
+	return isInSpacelessScript(before) || isInSpacelessScript(after)
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+// isInSpacelessScript checks if r belongs to scripts that don't separate words with spaces.
+func isInSpacelessScript(r rune) bool {
+	switch r {
+	case 'ー', '々': // prolonged sound mark and iteration mark of Japanese, which belong to "Common" script
+		return true
+	}
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
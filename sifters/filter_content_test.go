@@ -200,3 +200,110 @@ func TestContentMatchesAllRegexps(t *testing.T) {
 		}
 	})
 }
+
+func TestContentHasAnyWordWithOptions(t *testing.T) {
+	t.Run("matches words after normalization", func(t *testing.T) {
+		s := ContentHasAnyWordWithOptions([]string{"Free Sats"}, WordMatchOptions{Normalization: FullNormalization()}, Deny)
+
+		cs := []string{
+			"get free sats now",
+			"GET FREE SATS NOW",
+			"ｆｒｅｅ ｓａｔｓ",
+			"fr\u200bee s\u200dats",
+			"frее sаts", // Cyrillic "е" and "а"
+			"FREE SΑΤS", // Greek "Α" and "Τ"
+		}
+		for _, c := range cs {
+			res, err := s.Sift(inputWithContent(c))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject {
+				t.Fatalf("unexpected result for %q: %+v", c, res)
+			}
+		}
+	})
+
+	t.Run("matches words spelled with Greek capitals", func(t *testing.T) {
+		s := ContentHasAnyWordWithOptions([]string{"max", "nostr"}, WordMatchOptions{Normalization: FullNormalization()}, Deny)
+
+		cs := []string{
+			"ΜΑΧ",   // Greek "ΜΑΧ"
+			"ΝOSTR", // Greek "Ν"
+		}
+		for _, c := range cs {
+			res, err := s.Sift(inputWithContent(c))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject {
+				t.Fatalf("unexpected result for %q: %+v", c, res)
+			}
+		}
+	})
+
+	t.Run("matches only whole words if WholeWord is true", func(t *testing.T) {
+		s := ContentHasAnyWordWithOptions([]string{"zap", "スパム"}, WordMatchOptions{WholeWord: true}, Deny)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"zap me!", strfrui.ActionReject},
+			{"(zap)", strfrui.ActionReject},
+			{"zapper", strfrui.ActionAccept},
+			{"unzap", strfrui.ActionAccept},
+			{"zapzap zap", strfrui.ActionReject},
+			{"これはスパムです", strfrui.ActionReject},
+			{"スパムだ", strfrui.ActionReject},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+
+	t.Run("ignores words that are empty after normalization", func(t *testing.T) {
+		s := ContentHasAnyWordWithOptions([]string{"\u200b"}, WordMatchOptions{Normalization: FullNormalization()}, Deny)
+
+		res, err := s.Sift(inputWithContent("hello"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionAccept {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
+
+func TestContentHasAllWordsWithOptions(t *testing.T) {
+	s := ContentHasAllWordsWithOptions(
+		[]string{"nostr", "zap"},
+		WordMatchOptions{Normalization: TextNormalization{CaseFold: true}, WholeWord: true},
+		Allow,
+	)
+
+	tests := []struct {
+		content string
+		want    strfrui.Action
+	}{
+		{"Nostr has Zap", strfrui.ActionAccept},
+		{"NOSTR: ZAP!", strfrui.ActionAccept},
+		{"nostr has zaps", strfrui.ActionReject},
+		{"nostr only", strfrui.ActionReject},
+	}
+	for _, tt := range tests {
+		res, err := s.Sift(inputWithContent(tt.content))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != tt.want {
+			t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package sifters
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/jiftechnify/strfrui/sifters/internal/nip26"
+)
+
+// ValidDelegation makes an event-sifter that validates the delegation tag of a Nostr event, defined in [NIP-26].
+//
+// It checks that the delegation token is a valid signature by the delegator,
+// and that the event satisfies conditions (kind and created_at) in the delegation string.
+// Multiple kind conditions are interpreted as "kind is one of them".
+//
+// Events without a delegation tag are always accepted. Events with an invalid delegation tag are rejected with messages prefixed with "invalid:" by default.
+//
+// [NIP-26]: https://github.com/nostr-protocol/nips/blob/master/26.md
+func ValidDelegation() *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		_, err := nip26.Delegator(input.Event)
+		if errors.Is(err, nip26.ErrNoDelegation) {
+			return inputAlwaysAccept, nil
+		}
+		if err != nil {
+			return rejectWithMsg(fmt.Sprintf("invalid: delegation is invalid (%v)", err))
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: delegation is invalid")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
@@ -0,0 +1,170 @@
+package sifters
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+type testKey struct {
+	sk string
+	pk string
+}
+
+func newTestKey(t *testing.T) testKey {
+	t.Helper()
+
+	sk := nostr.GeneratePrivateKey()
+	pk, err := nostr.GetPublicKey(sk)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+	return testKey{sk: sk, pk: pk}
+}
+
+// delegatedEvent makes an event authored by delegatee, with a delegation tag signed by delegator.
+func delegatedEvent(t *testing.T, delegator, delegatee testKey, conds string, kind int, createdAt nostr.Timestamp) *nostr.Event {
+	t.Helper()
+
+	skBytes, _ := hex.DecodeString(delegator.sk)
+	sk, _ := btcec.PrivKeyFromBytes(skBytes)
+	hash := sha256.Sum256([]byte(fmt.Sprintf("nostr:delegation:%s:%s", delegatee.pk, conds)))
+	sig, err := schnorr.Sign(sk, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign delegation token: %v", err)
+	}
+
+	return &nostr.Event{
+		PubKey:    delegatee.pk,
+		Kind:      kind,
+		CreatedAt: createdAt,
+		Tags:      nostr.Tags{{"delegation", delegator.pk, conds, hex.EncodeToString(sig.Serialize())}},
+	}
+}
+
+func TestValidDelegation(t *testing.T) {
+	delegator, delegatee, other := newTestKey(t), newTestKey(t), newTestKey(t)
+	conds := "kind=1&kind=7&created_at>1000&created_at<2000"
+
+	s := ValidDelegation()
+
+	t.Run("accepts events with valid delegation or without delegation", func(t *testing.T) {
+		evs := []*nostr.Event{
+			delegatedEvent(t, delegator, delegatee, conds, 1, 1500),
+			delegatedEvent(t, delegator, delegatee, conds, 7, 1001),
+			delegatedEvent(t, delegator, delegatee, "", 30023, 5000),
+			{PubKey: delegatee.pk, Kind: 1, Tags: nostr.Tags{{"p", delegator.pk}}},
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+
+	t.Run("rejects events with invalid delegation", func(t *testing.T) {
+		forged := delegatedEvent(t, delegator, delegatee, conds, 1, 1500)
+		forged.PubKey = other.pk // token is not for this pubkey
+
+		tests := []struct {
+			ev      *nostr.Event
+			wantMsg string
+		}{
+			{forged, "invalid: delegation is invalid (delegation token is not signed by the delegator)"},
+			{delegatedEvent(t, delegator, delegatee, conds, 0, 1500), "invalid: delegation is invalid (event kind is not allowed by the delegation)"},
+			{delegatedEvent(t, delegator, delegatee, conds, 1, 2000), "invalid: delegation is invalid (event is created after the delegation period)"},
+			{delegatedEvent(t, delegator, delegatee, conds, 1, 1000), "invalid: delegation is invalid (event is created before the delegation period)"},
+			{delegatedEvent(t, delegator, delegatee, "kind=1&foo=bar", 1, 1500), `invalid: delegation is invalid (unknown delegation condition: "foo=bar")`},
+			{&nostr.Event{PubKey: delegatee.pk, Tags: nostr.Tags{{"delegation", delegator.pk}}}, "invalid: delegation is invalid (malformed delegation tag)"},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+}
+
+func TestAuthorListWithDelegation(t *testing.T) {
+	delegator, delegatee, other := newTestKey(t), newTestKey(t), newTestKey(t)
+
+	s := AuthorListWithDelegation([]string{delegator.pk}, Allow)
+
+	t.Run("accepts events by the delegator itself or validly delegated by the delegator", func(t *testing.T) {
+		evs := []*nostr.Event{
+			{PubKey: delegator.pk},
+			delegatedEvent(t, delegator, delegatee, "kind=1", 1, 1000),
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+
+	t.Run("rejects events not delegated or invalidly delegated", func(t *testing.T) {
+		forged := delegatedEvent(t, delegator, delegatee, "kind=1", 1, 1000)
+		forged.PubKey = other.pk
+
+		evs := []*nostr.Event{
+			{PubKey: delegatee.pk},
+			delegatedEvent(t, delegator, delegatee, "kind=1", 7, 1000),
+			forged,
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+}
+
+func TestAuthorMatcherWithDelegation(t *testing.T) {
+	delegator, delegatee := newTestKey(t), newTestKey(t)
+
+	s := AuthorMatcherWithDelegation(func(pk string) (bool, error) {
+		return pk == delegator.pk, nil
+	}, Deny)
+
+	res, err := s.Sift(inputWithEvent(delegatedEvent(t, delegator, delegatee, "kind=1", 1, 1000)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Action != strfrui.ActionReject {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	res, err = s.Sift(inputWithEvent(&nostr.Event{PubKey: delegatee.pk}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Action != strfrui.ActionAccept {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,365 @@
+package sifters
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"maps"
+	"math/bits"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+)
+
+// DuplicateContentConfig is a configuration of [DuplicateContentSifter].
+type DuplicateContentConfig struct {
+	// Contents posted by more than this number of distinct authors within Window are rejected. Defaults to 1.
+	MaxAuthors int
+
+	// Sliding time window in which contents are remembered. Defaults to 1 hour.
+	Window time.Duration
+
+	// Max Hamming distance between SimHashes of contents to be considered near-duplicates, up to 3.
+	// If zero, only exact duplicates (after normalization) are detected.
+	MaxSimHashDistance int
+
+	// Contents shorter than this (in characters, after normalization) are not checked, since short contents (e.g. "gm") are often identical by nature.
+	MinContentLength int
+
+	// Max number of distinct contents to remember. If exceeded, least recently seen contents are forgotten. Defaults to 100000.
+	MaxEntries int
+}
+
+// DuplicateContentSifter is an event-sifter that detects duplicate and near-duplicate contents posted by many authors,
+// which is typical for spam campaigns using many fresh pubkeys.
+//
+// It remembers fingerprints of normalized contents (see [FullNormalization]) seen within a sliding time window:
+// an exact hash to detect identical contents, and a [SimHash] of character 4-grams to detect lightly varied contents.
+// Each event is recorded even if it is rejected, and contents posted by more than MaxAuthors distinct authors are rejected.
+//
+// DuplicateContentSifter rejects with message: "blocked: same or similar content has been posted by too many users" by default.
+// If you want to customize rejection behavior,
+// call [DuplicateContentSifter.RejectWithMsg], [DuplicateContentSifter.RejectWithMsgFromInput] or [DuplicateContentSifter.ShadowReject] methods on it.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [DuplicateContent] function to construct an instance of DuplicateContentSifter.
+//
+// [SimHash]: https://en.wikipedia.org/wiki/SimHash
+type DuplicateContentSifter struct {
+	unit *SifterUnit
+	cfg  DuplicateContentConfig
+
+	mu        sync.Mutex
+	byHash    map[uint64]*list.Element
+	byBand    map[uint64][]*list.Element
+	lru       *list.List // of *contentEntry, most recently seen first
+	lastPrune time.Time
+
+	state    *utils.SnapshotFile
+	dirty    bool
+	lastSave time.Time
+}
+
+type contentEntry struct {
+	Hash     uint64               `json:"hash"`
+	SimHash  uint64               `json:"simHash"`
+	Authors  map[string]time.Time `json:"authors"` // pubkey -> last time the author posted the content
+	LastSeen time.Time            `json:"lastSeen"`
+}
+
+func (s *DuplicateContentSifter) Sift(input *strfrui.Input) (*strfrui.Result, error) {
+	return s.unit.Sift(input)
+}
+
+// ShadowReject sets the sifter's rejection behavior to "shadow-reject",
+// which pretend to accept the input but actually reject it.
+func (s *DuplicateContentSifter) ShadowReject() *DuplicateContentSifter {
+	s.unit.ShadowReject()
+	return s
+}
+
+// RejectWithMsg makes the sifter reject the input with the given message.
+func (s *DuplicateContentSifter) RejectWithMsg(msg string) *DuplicateContentSifter {
+	s.unit.RejectWithMsg(msg)
+	return s
+}
+
+// RejectWithMsgFromInput makes the sifter reject the input with the message derived from the input by the given function.
+func (s *DuplicateContentSifter) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string) *DuplicateContentSifter {
+	s.unit.RejectWithMsgFromInput(getMsg)
+	return s
+}
+
+// PersistTo makes the state of the sifter (fingerprints of recently seen contents) persisted to the file at the given path,
+// so that it survives restarts of the sifter.
+//
+// If the file already exists, the state is restored from it. After that, the state is saved to the file at most once per minute while it changes.
+func (s *DuplicateContentSifter) PersistTo(path string) (*DuplicateContentSifter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read duplicate content state file: %w", err)
+	}
+	if err == nil {
+		var entries []*contentEntry
+		if err := json.Unmarshal(b, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse duplicate content state file: %w", err)
+		}
+		s.clear()
+		// entries are saved in order of recency
+		for i := len(entries) - 1; i >= 0; i-- {
+			s.insert(entries[i])
+		}
+	}
+	s.state = &utils.SnapshotFile{Path: path}
+	return s, nil
+}
+
+// DuplicateContent makes a [DuplicateContentSifter] with the given configuration.
+//
+// For more details about the behavior of the sifter, see the doc of [DuplicateContentSifter] type.
+func DuplicateContent(cfg DuplicateContentConfig) *DuplicateContentSifter {
+	if cfg.MaxAuthors < 1 {
+		cfg.MaxAuthors = 1
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Hour
+	}
+	cfg.MaxSimHashDistance = max(0, min(cfg.MaxSimHashDistance, 3))
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 100000
+	}
+
+	s := &DuplicateContentSifter{
+		cfg: cfg,
+	}
+	s.clear()
+
+	normalization := FullNormalization()
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		content := strings.Join(strings.Fields(normalization.normalize(input.Event.Content)), " ")
+		if utf8.RuneCountInString(content) < cfg.MinContentLength {
+			return inputMismatch, nil
+		}
+		n := s.record(content, input.Event.PubKey)
+		return matchResultFromBool(n > cfg.MaxAuthors, nil)
+	}
+	defaultRejFn := internal.RejectWithMsg("blocked: same or similar content has been posted by too many users")
+	s.unit = newSifterUnit(matchInput, Deny, defaultRejFn)
+	return s
+}
+
+// record records that the author posted the (normalized) content,
+// and returns the number of distinct authors who posted the same or similar content within the window.
+func (s *DuplicateContentSifter) record(content string, author string) int {
+	now := clock.now()
+
+	s.mu.Lock()
+	n := s.recordLocked(content, author, now)
+	gen, entries := s.snapshotIfNeeded(now)
+	s.mu.Unlock()
+
+	// encode and write the state outside the lock, so that saving it doesn't block other inputs
+	if entries != nil {
+		s.save(gen, entries)
+	}
+	return n
+}
+
+// Caller must hold s.mu.
+func (s *DuplicateContentSifter) recordLocked(content string, author string, now time.Time) int {
+	s.prune(now)
+
+	h := hashContent(content)
+	sh := simHash(content)
+
+	elem := s.findSimilar(h, sh)
+	if elem == nil {
+		elem = s.insert(&contentEntry{
+			Hash:    h,
+			SimHash: sh,
+			Authors: make(map[string]time.Time),
+		})
+		for s.lru.Len() > s.cfg.MaxEntries {
+			s.remove(s.lru.Back())
+		}
+	} else {
+		s.lru.MoveToFront(elem)
+	}
+	e := elem.Value.(*contentEntry)
+	e.Authors[author] = now
+	e.LastSeen = now
+
+	for a, t := range e.Authors {
+		if now.Sub(t) >= s.cfg.Window {
+			delete(e.Authors, a)
+		}
+	}
+
+	s.dirty = true
+
+	return len(e.Authors)
+}
+
+// findSimilar finds the entry of the content that is identical or similar to the content with the given fingerprints.
+// Caller must hold s.mu.
+func (s *DuplicateContentSifter) findSimilar(h uint64, sh uint64) *list.Element {
+	if elem, ok := s.byHash[h]; ok {
+		return elem
+	}
+	if s.cfg.MaxSimHashDistance == 0 {
+		return nil
+	}
+	// SimHashes within distance 3 must share at least one of four 16-bit bands (pigeonhole principle)
+	for b := 0; b < 4; b++ {
+		for _, elem := range s.byBand[simHashBandKey(sh, b)] {
+			if bits.OnesCount64(elem.Value.(*contentEntry).SimHash^sh) <= s.cfg.MaxSimHashDistance {
+				return elem
+			}
+		}
+	}
+	return nil
+}
+
+// prune forgets contents that have not been seen within the window.
+// Contents are checked from the least recently seen one, at most once per minute. Caller must hold s.mu.
+func (s *DuplicateContentSifter) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+
+	for elem := s.lru.Back(); elem != nil; elem = s.lru.Back() {
+		if now.Sub(elem.Value.(*contentEntry).LastSeen) < s.cfg.Window {
+			break
+		}
+		s.remove(elem)
+		s.dirty = true
+	}
+}
+
+// Caller must hold s.mu.
+func (s *DuplicateContentSifter) clear() {
+	s.byHash = make(map[uint64]*list.Element)
+	s.byBand = make(map[uint64][]*list.Element)
+	s.lru = list.New()
+}
+
+// insert adds the entry as the most recently seen one. Caller must hold s.mu.
+func (s *DuplicateContentSifter) insert(e *contentEntry) *list.Element {
+	elem := s.lru.PushFront(e)
+	s.byHash[e.Hash] = elem
+	if s.cfg.MaxSimHashDistance > 0 {
+		for b := 0; b < 4; b++ {
+			k := simHashBandKey(e.SimHash, b)
+			s.byBand[k] = append(s.byBand[k], elem)
+		}
+	}
+	return elem
+}
+
+// Caller must hold s.mu.
+func (s *DuplicateContentSifter) remove(elem *list.Element) {
+	e := s.lru.Remove(elem).(*contentEntry)
+	if s.byHash[e.Hash] == elem {
+		delete(s.byHash, e.Hash)
+	}
+	if s.cfg.MaxSimHashDistance > 0 {
+		for b := 0; b < 4; b++ {
+			k := simHashBandKey(e.SimHash, b)
+			s.byBand[k] = removeElem(s.byBand[k], elem)
+			if len(s.byBand[k]) == 0 {
+				delete(s.byBand, k)
+			}
+		}
+	}
+}
+
+func removeElem(elems []*list.Element, target *list.Element) []*list.Element {
+	for i, elem := range elems {
+		if elem == target {
+			return append(elems[:i], elems[i+1:]...)
+		}
+	}
+	return elems
+}
+
+// snapshotIfNeeded copies the state to be saved to the state file, if any, at most once per minute.
+// It returns nil entries if the state needn't be saved. Caller must hold s.mu.
+func (s *DuplicateContentSifter) snapshotIfNeeded(now time.Time) (uint64, []*contentEntry) {
+	if s.state == nil || !s.dirty || now.Sub(s.lastSave) < time.Minute {
+		return 0, nil
+	}
+	s.lastSave = now
+	s.dirty = false
+
+	entries := make([]*contentEntry, 0, s.lru.Len())
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		e := *elem.Value.(*contentEntry)
+		e.Authors = maps.Clone(e.Authors)
+		entries = append(entries, &e)
+	}
+	return s.state.Next(), entries
+}
+
+// save writes the snapshot of the state to the state file.
+func (s *DuplicateContentSifter) save(gen uint64, entries []*contentEntry) {
+	err := s.state.Write(gen, func() ([]byte, error) {
+		return json.Marshal(entries)
+	})
+	if err != nil {
+		log.Printf("duplicateContent: failed to save state: %v", err)
+	}
+}
+
+func hashContent(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// simHash computes the SimHash of the text, using character 4-grams as features.
+// Character n-grams work for texts in any language, including ones that don't separate words with spaces.
+func simHash(text string) uint64 {
+	const n = 4
+
+	rs := []rune(text)
+	if len(rs) < n {
+		return hashContent(text)
+	}
+
+	var weights [64]int
+	for i := 0; i+n <= len(rs); i++ {
+		h := hashContent(string(rs[i : i+n]))
+		for b := 0; b < 64; b++ {
+			if h&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var sh uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			sh |= 1 << b
+		}
+	}
+	return sh
+}
+
+func simHashBandKey(sh uint64, band int) uint64 {
+	return uint64(band)<<16 | (sh>>(16*band))&0xffff
+}
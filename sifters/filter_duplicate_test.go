@@ -0,0 +1,129 @@
+package sifters
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func inputWithAuthorAndContent(pubkey, content string) *strfrui.Input {
+	return &strfrui.Input{
+		Event: &nostr.Event{
+			PubKey:  pubkey,
+			Content: content,
+		},
+	}
+}
+
+func TestDuplicateContent(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock.setFake(now)
+	t.Cleanup(func() {
+		clock.reset()
+	})
+
+	const spam = "get free bitcoin now at our giveaway, limited time offer for nostr users only! hurry up"
+
+	assertAction := func(t *testing.T, s strfrui.Sifter, input *strfrui.Input, want strfrui.Action) {
+		t.Helper()
+		res, err := s.Sift(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != want {
+			t.Fatalf("unexpected result for %+v: %+v", input.Event, res)
+		}
+	}
+
+	t.Run("rejects contents posted by too many authors", func(t *testing.T) {
+		s := DuplicateContent(DuplicateContentConfig{MaxAuthors: 2})
+
+		assertAction(t, s, inputWithAuthorAndContent("alice", spam), strfrui.ActionAccept)
+		// posts by the same author are not counted twice
+		assertAction(t, s, inputWithAuthorAndContent("alice", spam), strfrui.ActionAccept)
+		assertAction(t, s, inputWithAuthorAndContent("bob", spam), strfrui.ActionAccept)
+		// normalized contents are compared
+		assertAction(t, s, inputWithAuthorAndContent("carol", "  GET FREE BITCOIN NOW  at our giveaway, limited time offer for nostr users only! hurry up"), strfrui.ActionReject)
+		assertAction(t, s, inputWithAuthorAndContent("alice", spam), strfrui.ActionReject)
+		// other contents are not affected
+		assertAction(t, s, inputWithAuthorAndContent("dave", "hello world"), strfrui.ActionAccept)
+	})
+
+	t.Run("detects near-duplicates if MaxSimHashDistance is set", func(t *testing.T) {
+		variants := []string{
+			spam,
+			"get free bitcoin now at our giveaway, limited time offer for nostr users only!! hurry up",
+			"get free bitcoin now at our giveaway, limited time offer for nostr users only! hurry up 123",
+		}
+
+		exact := DuplicateContent(DuplicateContentConfig{MaxAuthors: 2})
+		near := DuplicateContent(DuplicateContentConfig{MaxAuthors: 2, MaxSimHashDistance: 3})
+		for i, v := range variants {
+			want := strfrui.ActionAccept
+			if i == 2 {
+				want = strfrui.ActionReject
+			}
+			assertAction(t, exact, inputWithAuthorAndContent(fmt.Sprintf("user%d", i), v), strfrui.ActionAccept)
+			assertAction(t, near, inputWithAuthorAndContent(fmt.Sprintf("user%d", i), v), want)
+		}
+		assertAction(t, near, inputWithAuthorAndContent("other", "completely different text about cats and dogs living together in harmony"), strfrui.ActionAccept)
+	})
+
+	t.Run("ignores short contents", func(t *testing.T) {
+		s := DuplicateContent(DuplicateContentConfig{MaxAuthors: 1, MinContentLength: 10})
+
+		for i := 0; i < 3; i++ {
+			assertAction(t, s, inputWithAuthorAndContent(fmt.Sprintf("user%d", i), "gm"), strfrui.ActionAccept)
+		}
+	})
+
+	t.Run("forgets contents out of the window", func(t *testing.T) {
+		s := DuplicateContent(DuplicateContentConfig{MaxAuthors: 1, Window: 10 * time.Minute})
+
+		clock.setFake(now)
+		assertAction(t, s, inputWithAuthorAndContent("alice", spam), strfrui.ActionAccept)
+
+		clock.setFake(now.Add(5 * time.Minute))
+		assertAction(t, s, inputWithAuthorAndContent("bob", spam), strfrui.ActionReject)
+
+		// alice's post is out of the window, but bob's one is not
+		clock.setFake(now.Add(12 * time.Minute))
+		assertAction(t, s, inputWithAuthorAndContent("carol", spam), strfrui.ActionReject)
+
+		clock.setFake(now.Add(30 * time.Minute))
+		assertAction(t, s, inputWithAuthorAndContent("dave", spam), strfrui.ActionAccept)
+	})
+
+	t.Run("forgets least recently seen contents if the number of contents exceeds MaxEntries", func(t *testing.T) {
+		clock.setFake(now)
+		s := DuplicateContent(DuplicateContentConfig{MaxAuthors: 1, MaxEntries: 2})
+
+		assertAction(t, s, inputWithAuthorAndContent("alice", "content 1"), strfrui.ActionAccept)
+		assertAction(t, s, inputWithAuthorAndContent("alice", "content 2"), strfrui.ActionAccept)
+		assertAction(t, s, inputWithAuthorAndContent("alice", "content 3"), strfrui.ActionAccept)
+
+		assertAction(t, s, inputWithAuthorAndContent("bob", "content 1"), strfrui.ActionAccept)
+		assertAction(t, s, inputWithAuthorAndContent("bob", "content 3"), strfrui.ActionReject)
+	})
+
+	t.Run("persists the state to a file", func(t *testing.T) {
+		clock.setFake(now)
+		path := filepath.Join(t.TempDir(), "duplicate.json")
+
+		s1, err := DuplicateContent(DuplicateContentConfig{MaxAuthors: 1, MaxSimHashDistance: 3}).PersistTo(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertAction(t, s1, inputWithAuthorAndContent("alice", spam), strfrui.ActionAccept)
+
+		s2, err := DuplicateContent(DuplicateContentConfig{MaxAuthors: 1, MaxSimHashDistance: 3}).PersistTo(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertAction(t, s2, inputWithAuthorAndContent("bob", spam+"!"), strfrui.ActionReject)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal/nip26"
 	"github.com/jiftechnify/strfrui/sifters/internal/utils"
 	"github.com/nbd-wtf/go-nostr"
 )
@@ -52,6 +53,51 @@ func AuthorList(authors []string, mode Mode) *SifterUnit {
 	return newSifterUnit(matchInput, mode, defaultRejFn)
 }
 
+// AuthorMatcherWithDelegation is a variant of [AuthorMatcher] that treats events validly delegated by [NIP-26] as authored by the delegator.
+// That is, the matcher is applied to the delegator's pubkey if the event has a valid delegation tag, otherwise to the pubkey of the event.
+//
+// Events with an invalid delegation tag are treated as ordinary events. Use [ValidDelegation] to reject them.
+//
+// [NIP-26]: https://github.com/nostr-protocol/nips/blob/master/26.md
+func AuthorMatcherWithDelegation(matcher func(string) (bool, error), mode Mode) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		return matchResultFromBool(matcher(effectiveAuthor(input.Event)))
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: event author is not in the whitelist",
+		"blocked: event author is in the blacklist",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+// AuthorListWithDelegation is a variant of [AuthorList] that treats events validly delegated by [NIP-26] as authored by the delegator.
+// That is, the delegator's pubkey is checked against the list if the event has a valid delegation tag, otherwise the pubkey of the event is checked.
+//
+// Events with an invalid delegation tag are treated as ordinary events. Use [ValidDelegation] to reject them.
+//
+// [NIP-26]: https://github.com/nostr-protocol/nips/blob/master/26.md
+func AuthorListWithDelegation(authors []string, mode Mode) *SifterUnit {
+	authorSet := utils.SliceToSet(authors)
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		_, ok := authorSet[effectiveAuthor(input.Event)]
+		return matchResultFromBool(ok, nil)
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: event author is not in the whitelist",
+		"blocked: event author is in the blacklist",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+func effectiveAuthor(ev *nostr.Event) string {
+	if delegator, err := nip26.Delegator(ev); err == nil {
+		return delegator
+	}
+	return ev.PubKey
+}
+
 var (
 	// Non-Parametarized Replaceable events: kind 0, 3, 41 and 10000 <= kind < 20000
 	KindsAllNonParamReplaceable = func(k int) bool {
@@ -0,0 +1,65 @@
+package sifters
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+// ExpirationPolicy describes how [Expiration] sifter treats the expiration tag of events, defined in [NIP-40].
+//
+// Events whose expiration is already in the past at receipt and events with a malformed expiration tag are always rejected.
+// Other conditions are optional.
+//
+// [NIP-40]: https://github.com/nostr-protocol/nips/blob/master/40.md
+type ExpirationPolicy struct {
+	// If non-zero, events that expire later than this duration after receipt are rejected.
+	MaxFutureDelta time.Duration
+
+	// If non-nil, events of kinds that match this function must have an expiration tag.
+	// You can use kind matchers such as [KindsAllEphemeral] here.
+	RequiredForKinds func(int) bool
+}
+
+// Expiration makes an event-sifter that checks the expiration tag of a Nostr event ([NIP-40]) according to the given policy.
+//
+// The expiration is compared with the time of receipt of the event ([strfrui.Input.ReceivedAt]), not the current time.
+// If ReceivedAt is not available (i.e. zero), the current time is used instead.
+//
+// This sifter rejects events with messages prefixed with "invalid:" by default.
+//
+// [NIP-40]: https://github.com/nostr-protocol/nips/blob/master/40.md
+func Expiration(policy ExpirationPolicy) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		expTag := input.Event.Tags.GetFirst([]string{"expiration", ""})
+		if expTag == nil {
+			if policy.RequiredForKinds != nil && policy.RequiredForKinds(input.Event.Kind) {
+				return rejectWithMsg("invalid: event must have an expiration tag")
+			}
+			return inputMatch, nil
+		}
+
+		exp, err := strconv.ParseInt(expTag.Value(), 10, 64)
+		if err != nil || exp < 0 {
+			return rejectWithMsg("invalid: expiration tag must have a valid timestamp")
+		}
+		expiration := time.Unix(exp, 0)
+
+		receivedAt := clock.now()
+		if input.ReceivedAt != 0 {
+			receivedAt = time.Unix(int64(input.ReceivedAt), 0)
+		}
+		if !expiration.After(receivedAt) {
+			return rejectWithMsg("invalid: event has already expired")
+		}
+		if policy.MaxFutureDelta != 0 && expiration.After(receivedAt.Add(policy.MaxFutureDelta)) {
+			return rejectWithMsg(fmt.Sprintf("invalid: expiration is too far in the future (max: %v after)", policy.MaxFutureDelta))
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: expiration of the event is invalid")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
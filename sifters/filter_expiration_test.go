@@ -0,0 +1,95 @@
+package sifters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestExpiration(t *testing.T) {
+	inputWithExpiration := func(kind int, exp string, receivedAt uint64) *strfrui.Input {
+		ev := &nostr.Event{Kind: kind, Tags: nostr.Tags{}}
+		if exp != "" {
+			ev.Tags = append(ev.Tags, nostr.Tag{"expiration", exp})
+		}
+		return &strfrui.Input{
+			Event:      ev,
+			ReceivedAt: receivedAt,
+		}
+	}
+
+	s := Expiration(ExpirationPolicy{
+		MaxFutureDelta:   24 * time.Hour,
+		RequiredForKinds: KindsAllEphemeral,
+	})
+
+	t.Run("accepts events with valid expiration", func(t *testing.T) {
+		inputs := []*strfrui.Input{
+			inputWithExpiration(1, "", 1000),
+			inputWithExpiration(1, "1001", 1000),
+			inputWithExpiration(1, "87400", 1000), // just 24h after
+			inputWithExpiration(20000, "2000", 1000),
+		}
+
+		for _, in := range inputs {
+			res, err := s.Sift(in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+
+	t.Run("rejects events with invalid expiration", func(t *testing.T) {
+		tests := []struct {
+			input   *strfrui.Input
+			wantMsg string
+		}{
+			{inputWithExpiration(1, "1000", 1000), "invalid: event has already expired"},
+			{inputWithExpiration(1, "999", 1000), "invalid: event has already expired"},
+			{inputWithExpiration(1, "87401", 1000), "invalid: expiration is too far in the future (max: 24h0m0s after)"},
+			{inputWithExpiration(1, "tomorrow", 1000), "invalid: expiration tag must have a valid timestamp"},
+			{inputWithExpiration(1, "-1", 1000), "invalid: expiration tag must have a valid timestamp"},
+			{inputWithExpiration(20000, "", 1000), "invalid: event must have an expiration tag"},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+
+	t.Run("uses current time if receivedAt is not available", func(t *testing.T) {
+		clock.setFake(time.Unix(1000, 0))
+		t.Cleanup(func() {
+			clock.reset()
+		})
+
+		s := Expiration(ExpirationPolicy{})
+
+		res, err := s.Sift(inputWithExpiration(1, "999", 0))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionReject {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		res, err = s.Sift(inputWithExpiration(1, "1001", 0))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionAccept {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
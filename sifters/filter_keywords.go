@@ -0,0 +1,189 @@
+package sifters
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal/ahocorasick"
+)
+
+// KeywordSifter is an event-sifter that checks if a content of a Nostr event has any keyword in a (possibly large) list of keywords.
+//
+// Unlike [ContentHasAnyWord], it searches all keywords at once with an [Aho-Corasick] automaton, so the cost of matching doesn't grow with the number of keywords.
+// The automaton is built only once on construction (and on reload), and is safe for concurrent use.
+//
+// To know which keyword matched (e.g. for logging), register a callback by [KeywordSifter.OnMatch].
+// The list of keywords can be replaced at runtime by [KeywordSifter.Reload] or [KeywordSifter.ReloadFile].
+//
+// KeywordSifter rejects with message: "blocked: content has one of forbidden words" (in Deny mode)
+// or "blocked: content must have one of keywords to be accepted" (in Allow mode) by default.
+// If you want to customize rejection behavior,
+// call [KeywordSifter.RejectWithMsg], [KeywordSifter.RejectWithMsgFromInput] or [KeywordSifter.ShadowReject] methods on it.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [ContentHasAnyKeyword] or [ContentHasAnyKeywordFromFile] function to construct an instance of KeywordSifter.
+//
+// [Aho-Corasick]: https://en.wikipedia.org/wiki/Aho%E2%80%93Corasick_algorithm
+type KeywordSifter struct {
+	unit    *SifterUnit
+	opts    WordMatchOptions
+	path    string
+	dict    atomic.Pointer[keywordDict]
+	onMatch atomic.Pointer[func(*strfrui.Input, string)]
+}
+
+type keywordDict struct {
+	matcher  *ahocorasick.Matcher
+	keywords []string // original keywords, before normalization
+}
+
+func (s *KeywordSifter) Sift(input *strfrui.Input) (*strfrui.Result, error) {
+	return s.unit.Sift(input)
+}
+
+// OnMatch registers a callback that is called with the input and the matched keyword every time content of an input matches a keyword.
+// The matched keyword is reported as it is in the keyword list (i.e. before normalization).
+func (s *KeywordSifter) OnMatch(f func(input *strfrui.Input, keyword string)) *KeywordSifter {
+	s.onMatch.Store(&f)
+	return s
+}
+
+// FindKeyword searches the text for keywords and returns the first keyword found, as it is in the keyword list.
+func (s *KeywordSifter) FindKeyword(text string) (string, bool) {
+	dict := s.dict.Load()
+	text = s.opts.Normalization.normalize(text)
+
+	found := -1
+	dict.matcher.Iterate(text, func(m ahocorasick.Match) bool {
+		if s.opts.WholeWord && !(isWordBoundary(text, m.Start) && isWordBoundary(text, m.End)) {
+			return true
+		}
+		found = m.Pattern
+		return false
+	})
+	if found < 0 {
+		return "", false
+	}
+	return dict.keywords[found], true
+}
+
+// Reload replaces the list of keywords with the given one.
+// Inputs being sifted during reload are checked against either the old or the new list.
+func (s *KeywordSifter) Reload(keywords []string) {
+	s.dict.Store(buildKeywordDict(keywords, s.opts.Normalization))
+}
+
+// ReloadFile re-reads the keyword file from which the sifter was constructed, and replaces the list of keywords with the file content.
+// If it fails to read the file, the current list is kept.
+//
+// It returns an error if the sifter was not constructed from a file (i.e. by [ContentHasAnyKeyword]).
+func (s *KeywordSifter) ReloadFile() error {
+	if s.path == "" {
+		return fmt.Errorf("sifter was not constructed from a file")
+	}
+	keywords, err := readKeywordFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.Reload(keywords)
+	return nil
+}
+
+// ShadowReject sets the sifter's rejection behavior to "shadow-reject",
+// which pretend to accept the input but actually reject it.
+func (s *KeywordSifter) ShadowReject() *KeywordSifter {
+	s.unit.ShadowReject()
+	return s
+}
+
+// RejectWithMsg makes the sifter reject the input with the given message.
+func (s *KeywordSifter) RejectWithMsg(msg string) *KeywordSifter {
+	s.unit.RejectWithMsg(msg)
+	return s
+}
+
+// RejectWithMsgFromInput makes the sifter reject the input with the message derived from the input by the given function.
+func (s *KeywordSifter) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string) *KeywordSifter {
+	s.unit.RejectWithMsgFromInput(getMsg)
+	return s
+}
+
+// ContentHasAnyKeyword makes a [KeywordSifter] that checks if a content of a Nostr event has any keyword in the given list,
+// with normalization and word boundary rules specified by opts.
+//
+// For more details about the behavior of the sifter, see the doc of [KeywordSifter] type.
+func ContentHasAnyKeyword(keywords []string, opts WordMatchOptions, mode Mode) *KeywordSifter {
+	s := &KeywordSifter{
+		opts: opts,
+	}
+	s.Reload(keywords)
+
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		kw, ok := s.FindKeyword(input.Event.Content)
+		if !ok {
+			return inputMismatch, nil
+		}
+		if f := s.onMatch.Load(); f != nil {
+			(*f)(input, kw)
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: content must have one of keywords to be accepted",
+		"blocked: content has one of forbidden words",
+	)
+	s.unit = newSifterUnit(matchInput, mode, defaultRejFn)
+	return s
+}
+
+// ContentHasAnyKeywordFromFile is a variant of [ContentHasAnyKeyword] that reads the list of keywords from the file at the given path.
+//
+// The file should have one keyword per line. Leading and trailing whitespaces of each line are trimmed, and empty lines and lines starting with "#" are ignored.
+// You can re-read the file later by [KeywordSifter.ReloadFile].
+func ContentHasAnyKeywordFromFile(path string, opts WordMatchOptions, mode Mode) (*KeywordSifter, error) {
+	keywords, err := readKeywordFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := ContentHasAnyKeyword(keywords, opts, mode)
+	s.path = path
+	return s, nil
+}
+
+func buildKeywordDict(keywords []string, n TextNormalization) *keywordDict {
+	normalized := make([]string, len(keywords))
+	for i, kw := range keywords {
+		normalized[i] = n.normalize(kw)
+	}
+	return &keywordDict{
+		matcher:  ahocorasick.New(normalized),
+		keywords: keywords,
+	}
+}
+
+func readKeywordFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyword file: %w", err)
+	}
+	defer f.Close()
+
+	keywords := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keywords = append(keywords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keyword file: %w", err)
+	}
+	return keywords, nil
+}
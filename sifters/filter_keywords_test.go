@@ -0,0 +1,161 @@
+package sifters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+)
+
+func TestContentHasAnyKeyword(t *testing.T) {
+	t.Run("rejects contents that have any of the keywords", func(t *testing.T) {
+		s := ContentHasAnyKeyword([]string{"free sats", "airdrop", "he", "she", "hers"}, WordMatchOptions{}, Deny)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"get free sats now!", strfrui.ActionReject},
+			{"join the airdrop", strfrui.ActionReject},
+			{"ushers", strfrui.ActionReject},
+			{"nothing to see", strfrui.ActionAccept},
+			{"", strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+
+	t.Run("applies normalization and word boundaries", func(t *testing.T) {
+		s := ContentHasAnyKeyword([]string{"Free Sats", "zap"}, WordMatchOptions{Normalization: FullNormalization(), WholeWord: true}, Deny)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"ＦＲＥＥ ＳＡＴＳ", strfrui.ActionReject},
+			{"zapper zap", strfrui.ActionReject},
+			{"zapper", strfrui.ActionAccept},
+			{"carefree satsuma", strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+
+	t.Run("reports the matched keyword as it is in the list", func(t *testing.T) {
+		var matched string
+		s := ContentHasAnyKeyword([]string{"nostr", "Free Sats"}, WordMatchOptions{Normalization: TextNormalization{CaseFold: true}}, Deny).
+			OnMatch(func(_ *strfrui.Input, kw string) {
+				matched = kw
+			})
+
+		res, err := s.Sift(inputWithContent("FREE SATS HERE"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionReject {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		if matched != "Free Sats" {
+			t.Fatalf("unexpected matched keyword: %q", matched)
+		}
+	})
+
+	t.Run("finds keywords in a large list", func(t *testing.T) {
+		keywords := make([]string, 0, 20000)
+		for i := 0; i < 20000; i++ {
+			keywords = append(keywords, fmt.Sprintf("spam phrase %05d", i))
+		}
+		s := ContentHasAnyKeyword(keywords, WordMatchOptions{}, Deny)
+
+		if kw, ok := s.FindKeyword("this is spam phrase 12345!"); !ok || kw != "spam phrase 12345" {
+			t.Fatalf("unexpected result: %q, %v", kw, ok)
+		}
+		if _, ok := s.FindKeyword("this is spam phrase 2"); ok {
+			t.Fatal("unexpected match")
+		}
+	})
+
+	t.Run("is safe for concurrent use while reloading", func(t *testing.T) {
+		s := ContentHasAnyKeyword([]string{"foo"}, WordMatchOptions{}, Deny)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if _, err := s.Sift(inputWithContent("foo bar")); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				s.Reload([]string{"foo", "bar"})
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestContentHasAnyKeywordFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keywords.txt")
+	if err := os.WriteFile(path, []byte("# spam phrases\nfree sats\n\n  airdrop  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ContentHasAnyKeywordFromFile(path, WordMatchOptions{}, Deny)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertAction := func(t *testing.T, content string, want strfrui.Action) {
+		t.Helper()
+		res, err := s.Sift(inputWithContent(content))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != want {
+			t.Fatalf("unexpected result for %q: %+v", content, res)
+		}
+	}
+
+	assertAction(t, "join the airdrop", strfrui.ActionReject)
+	assertAction(t, "# spam phrases", strfrui.ActionAccept)
+	assertAction(t, "giveaway!", strfrui.ActionAccept)
+
+	if err := os.WriteFile(path, []byte("giveaway\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReloadFile(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertAction(t, "join the airdrop", strfrui.ActionAccept)
+	assertAction(t, "giveaway!", strfrui.ActionReject)
+
+	// the current list is kept if reloading fails
+	os.Remove(path)
+	if err := s.ReloadFile(); err == nil {
+		t.Fatal("expected error")
+	}
+	assertAction(t, "giveaway!", strfrui.ActionReject)
+
+	if err := ContentHasAnyKeyword(nil, WordMatchOptions{}, Deny).ReloadFile(); err == nil {
+		t.Fatal("expected error")
+	}
+}
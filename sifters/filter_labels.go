@@ -0,0 +1,160 @@
+package sifters
+
+import (
+	"regexp"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// SensitiveContentPatterns describes patterns of sensitive contents, which must be labelled with a content-warning tag.
+// Events match if any of the patterns matches.
+type SensitiveContentPatterns struct {
+	// Words in contents. They are matched as whole words, after normalization by [FullNormalization].
+	Words []string
+
+	// Regular expressions matched with contents.
+	ContentRegexps []*regexp.Regexp
+
+	// Domains of links in events. A domain also matches its subdomains. See [LinkDomainList] for how links are extracted and normalized.
+	Domains []string
+
+	// Regular expressions matched with URLs of links in events.
+	URLRegexps []*regexp.Regexp
+}
+
+// RequireContentWarning makes an event-sifter that requires events whose content or links match the given sensitive patterns
+// to have a content-warning tag, defined in [NIP-36].
+//
+// Events that have a content-warning tag and events that don't match the patterns are always accepted.
+// Events labelled in the "content-warning" namespace by "L" and "l" tags (defined in [NIP-32]), which NIP-36 also allows, are considered to have a content warning.
+//
+// This sifter rejects with message: "blocked: sensitive content must be labelled with a content-warning tag" by default.
+//
+// [NIP-36]: https://github.com/nostr-protocol/nips/blob/master/36.md
+// [NIP-32]: https://github.com/nostr-protocol/nips/blob/master/32.md
+func RequireContentWarning(patterns SensitiveContentPatterns) *SifterUnit {
+	normalization := FullNormalization()
+	words := normalizeWords(patterns.Words, normalization)
+
+	domainSet := normalizedDomainSet(patterns.Domains)
+
+	isSensitive := func(ev *nostr.Event) bool {
+		if len(words) > 0 {
+			content := normalization.normalize(ev.Content)
+			for _, w := range words {
+				if containsWord(content, w, true) {
+					return true
+				}
+			}
+		}
+		for _, r := range patterns.ContentRegexps {
+			if r.MatchString(ev.Content) {
+				return true
+			}
+		}
+		if len(patterns.URLRegexps) > 0 {
+			for _, rawURL := range extractURLs(ev) {
+				for _, r := range patterns.URLRegexps {
+					if r.MatchString(rawURL) {
+						return true
+					}
+				}
+			}
+		}
+		if len(domainSet) > 0 {
+			for _, h := range extractLinkHosts(ev) {
+				if domainSetHasSuffixOf(domainSet, h) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		if hasContentWarning(input.Event) || !isSensitive(input.Event) {
+			return inputMatch, nil
+		}
+		return inputMismatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("blocked: sensitive content must be labelled with a content-warning tag")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+// hasContentWarning checks if the event has a content-warning tag, or a label in the "content-warning" namespace (as described in NIP-36).
+func hasContentWarning(ev *nostr.Event) bool {
+	for _, tag := range ev.Tags {
+		if len(tag) >= 1 && tag[0] == "content-warning" {
+			return true
+		}
+	}
+	for _, l := range eventLabels(ev) {
+		if l.Namespace == "content-warning" {
+			return true
+		}
+	}
+	return false
+}
+
+// EventLabel specifies a label attached to events by "L" and "l" tags, defined in [NIP-32].
+//
+// [NIP-32]: https://github.com/nostr-protocol/nips/blob/master/32.md
+type EventLabel struct {
+	// Namespace of the label (e.g. "ISO-639-1", "com.example.ontology").
+	// As per NIP-32, "ugc" is implied for "l" tags without namespaces.
+	Namespace string
+
+	// Value of the label. If empty, it matches any label in the namespace.
+	Value string
+}
+
+// LabelList makes an event-sifter that checks if a Nostr event is labelled with any of the given labels by "L" and "l" tags, defined in [NIP-32].
+//
+// An [EventLabel] with empty Value matches events that have a "L" tag or a "l" tag with the namespace.
+//
+// [NIP-32]: https://github.com/nostr-protocol/nips/blob/master/32.md
+func LabelList(labels []EventLabel, mode Mode) *SifterUnit {
+	labelSet := make(map[EventLabel]struct{}, len(labels))
+	for _, l := range labels {
+		labelSet[l] = struct{}{}
+	}
+
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		for _, l := range eventLabels(input.Event) {
+			if _, ok := labelSet[l]; ok {
+				return inputMatch, nil
+			}
+			if _, ok := labelSet[EventLabel{Namespace: l.Namespace}]; ok {
+				return inputMatch, nil
+			}
+		}
+		return inputMismatch, nil
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: event must be labelled with one of allowed labels",
+		"blocked: event is labelled with a forbidden label",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+// eventLabels returns labels attached to the event.
+// Namespaces declared by "L" tags are returned as labels with empty values.
+func eventLabels(ev *nostr.Event) []EventLabel {
+	labels := make([]EventLabel, 0)
+	for _, tag := range ev.Tags {
+		switch {
+		case len(tag) >= 2 && tag[0] == "L":
+			labels = append(labels, EventLabel{Namespace: tag[1]})
+		case len(tag) >= 2 && tag[0] == "l":
+			ns := "ugc"
+			if len(tag) >= 3 && tag[2] != "" {
+				ns = tag[2]
+			}
+			labels = append(labels, EventLabel{Namespace: ns, Value: tag[1]})
+		}
+	}
+	return labels
+}
@@ -0,0 +1,109 @@
+package sifters
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestRequireContentWarning(t *testing.T) {
+	s := RequireContentWarning(SensitiveContentPatterns{
+		Words:          []string{"nsfw"},
+		ContentRegexps: []*regexp.Regexp{regexp.MustCompile(`(?i)\bgore\b`)},
+		Domains:        []string{"adult.example"},
+		URLRegexps:     []*regexp.Regexp{regexp.MustCompile(`/nsfw/`)},
+	})
+
+	cw := nostr.Tags{{"content-warning", "nudity"}}
+
+	tests := []struct {
+		name string
+		ev   *nostr.Event
+		want strfrui.Action
+	}{
+		{"not sensitive", &nostr.Event{Content: "hello, world"}, strfrui.ActionAccept},
+		{"sensitive word", &nostr.Event{Content: "#NSFW pic"}, strfrui.ActionReject},
+		{"sensitive word (obfuscated)", &nostr.Event{Content: "ｎｓｆｗ pic"}, strfrui.ActionReject},
+		{"sensitive word (Greek capitals)", &nostr.Event{Content: "ΝSFW pic"}, strfrui.ActionReject},
+		{"sensitive word as a part of another word", &nostr.Event{Content: "nsfwish"}, strfrui.ActionAccept},
+		{"sensitive content pattern", &nostr.Event{Content: "some Gore here"}, strfrui.ActionReject},
+		{"sensitive domain", &nostr.Event{Content: "see https://www.adult.example/1"}, strfrui.ActionReject},
+		{"sensitive subdomain in imeta", &nostr.Event{Tags: nostr.Tags{{"imeta", "url https://cdn.adult.example/1.png"}}}, strfrui.ActionReject},
+		{"sensitive URL pattern", &nostr.Event{Content: "https://img.example.com/nsfw/1.png"}, strfrui.ActionReject},
+		{"sensitive but has content-warning", &nostr.Event{Content: "nsfw https://adult.example", Tags: cw}, strfrui.ActionAccept},
+		{"content-warning without reason", &nostr.Event{Content: "nsfw", Tags: nostr.Tags{{"content-warning"}}}, strfrui.ActionAccept},
+		{"content-warning label", &nostr.Event{Content: "nsfw", Tags: nostr.Tags{{"L", "content-warning"}, {"l", "nudity", "content-warning"}}}, strfrui.ActionAccept},
+		{"content-warning label without namespace tag", &nostr.Event{Content: "nsfw", Tags: nostr.Tags{{"l", "nudity", "content-warning"}}}, strfrui.ActionAccept},
+		{"label in other namespace", &nostr.Event{Content: "nsfw", Tags: nostr.Tags{{"L", "other"}, {"l", "nudity", "other"}}}, strfrui.ActionReject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		})
+	}
+}
+
+func TestLabelList(t *testing.T) {
+	t.Run("Deny mode rejects events with any of the labels", func(t *testing.T) {
+		s := LabelList([]EventLabel{
+			{Namespace: "content-warning", Value: "nudity"},
+			{Namespace: "com.example.spam"},
+			{Namespace: "ugc", Value: "spam"},
+		}, Deny)
+
+		tests := []struct {
+			name string
+			tags nostr.Tags
+			want strfrui.Action
+		}{
+			{"no labels", nostr.Tags{}, strfrui.ActionAccept},
+			{"label with value", nostr.Tags{{"L", "content-warning"}, {"l", "nudity", "content-warning"}}, strfrui.ActionReject},
+			{"other value in the namespace", nostr.Tags{{"L", "content-warning"}, {"l", "violence", "content-warning"}}, strfrui.ActionAccept},
+			{"any value in the namespace", nostr.Tags{{"l", "whatever", "com.example.spam"}}, strfrui.ActionReject},
+			{"namespace declared by L tag", nostr.Tags{{"L", "com.example.spam"}}, strfrui.ActionReject},
+			{"implied ugc namespace", nostr.Tags{{"l", "spam"}}, strfrui.ActionReject},
+			{"same value in other namespace", nostr.Tags{{"l", "nudity", "other"}}, strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res, err := s.Sift(inputWithEvent(&nostr.Event{Tags: tt.tags}))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if res.Action != tt.want {
+					t.Fatalf("unexpected result: %+v", res)
+				}
+			})
+		}
+	})
+
+	t.Run("Allow mode accepts only events with any of the labels", func(t *testing.T) {
+		s := LabelList([]EventLabel{{Namespace: "ISO-639-1", Value: "ja"}}, Allow)
+
+		tests := []struct {
+			tags nostr.Tags
+			want strfrui.Action
+		}{
+			{nostr.Tags{{"L", "ISO-639-1"}, {"l", "ja", "ISO-639-1"}}, strfrui.ActionAccept},
+			{nostr.Tags{{"L", "ISO-639-1"}, {"l", "en", "ISO-639-1"}}, strfrui.ActionReject},
+			{nostr.Tags{}, strfrui.ActionReject},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(&nostr.Event{Tags: tt.tags}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %v: %+v", tt.tags, res)
+			}
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package sifters
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/nbd-wtf/go-nostr"
+	"golang.org/x/net/idna"
+)
+
+// LinkDomainList makes an event-sifter that checks if links in a Nostr event point to domains in the given list.
+//
+// Links are extracted from the content (http:// and https:// URLs) and URL-bearing tags ("r", "url" and "imeta").
+// Hosts of links and domains in the list are normalized before matching: converted to lowercase ASCII (punycode) form and stripped of a leading "www.".
+// A domain in the list also matches its subdomains (e.g. "example.com" matches "spam.example.com").
+//
+// If mode is Allow, it accepts events whose links all point to domains in the list (events without links are accepted).
+// If mode is Deny, it rejects events that have any link to domains in the list.
+func LinkDomainList(domains []string, mode Mode) *SifterUnit {
+	domainSet := normalizedDomainSet(domains)
+
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		hosts := extractLinkHosts(input.Event)
+		if mode == Allow {
+			for _, h := range hosts {
+				if !domainSetHasSuffixOf(domainSet, h) {
+					return inputMismatch, nil
+				}
+			}
+			return inputMatch, nil
+		}
+		for _, h := range hosts {
+			if domainSetHasSuffixOf(domainSet, h) {
+				return inputMatch, nil
+			}
+		}
+		return inputMismatch, nil
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: links to domains other than allowed ones are not allowed",
+		"blocked: event has a link to a forbidden domain",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+// MaxLinks makes an event-sifter that checks if the number of links in a Nostr event is less than or equal to the given limit.
+//
+// Links are extracted in the same way as [LinkDomainList]. The same URL appearing multiple times is counted once.
+func MaxLinks(limit int) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		return matchResultFromBool(len(extractURLs(input.Event)) <= limit, nil)
+	}
+	defaultRejFn := internal.RejectWithMsg(fmt.Sprintf("blocked: event has too many links (max: %d)", limit))
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+var urlInContentRegexp = regexp.MustCompile(`(?i)https?://[^\s<>"'` + "`" + `]+`)
+
+// extractURLs extracts http(s) URLs from the content and URL-bearing tags of the event, without duplicates.
+func extractURLs(ev *nostr.Event) []string {
+	urls := make([]string, 0)
+	seen := make(map[string]struct{})
+	add := func(u string) {
+		if _, ok := seen[u]; ok {
+			return
+		}
+		seen[u] = struct{}{}
+		urls = append(urls, u)
+	}
+
+	for _, u := range urlInContentRegexp.FindAllString(ev.Content, -1) {
+		add(trimURLTrailer(u))
+	}
+	for _, tag := range ev.Tags {
+		switch tag.Key() {
+		case "r", "url":
+			if isHTTPURL(tag.Value()) {
+				add(tag.Value())
+			}
+		case "imeta":
+			// NIP-92: each element is "<key> <value>"
+			for _, elem := range tag[1:] {
+				k, v, ok := strings.Cut(elem, " ")
+				if ok && (k == "url" || k == "fallback") && isHTTPURL(v) {
+					add(v)
+				}
+			}
+		}
+	}
+	return urls
+}
+
+// trimURLTrailer strips trailing punctuations, which are likely not a part of the URL in texts, from u.
+// Closing parentheses are stripped only if they are unbalanced (e.g. "(see https://example.com)").
+func trimURLTrailer(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		switch {
+		case strings.IndexByte(".,;:!?", last) >= 0:
+			u = u[:len(u)-1]
+		case last == ')' && strings.Count(u, "(") < strings.Count(u, ")"):
+			u = u[:len(u)-1]
+		default:
+			return u
+		}
+	}
+	return u
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return (scheme == "http" || scheme == "https") && u.Host != ""
+}
+
+// extractLinkHosts returns the normalized hosts of links in the event.
+func extractLinkHosts(ev *nostr.Event) []string {
+	hosts := make([]string, 0)
+	for _, rawURL := range extractURLs(ev) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+		if h, ok := normalizeHost(u.Host); ok {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// normalizeHost normalizes a host (or a domain): strips the port and the trailing dot, converts it to lowercase ASCII (punycode) form, and strips a leading "www.".
+func normalizeHost(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if host == "" {
+		return "", false
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		// fall back to lowercasing hosts that are not valid IDNs (e.g. containing underscores)
+		ascii = strings.ToLower(host)
+	}
+	return strings.TrimPrefix(ascii, "www."), true
+}
+
+// normalizedDomainSet makes a set of normalized domains. Invalid domains are ignored.
+func normalizedDomainSet(domains []string) map[string]struct{} {
+	domainSet := make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		if nd, ok := normalizeHost(d); ok {
+			domainSet[nd] = struct{}{}
+		}
+	}
+	return domainSet
+}
+
+// domainSetHasSuffixOf checks if the host or any of its parent domains is in the set.
+func domainSetHasSuffixOf(domainSet map[string]struct{}, host string) bool {
+	for {
+		if _, ok := domainSet[host]; ok {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return false
+		}
+		host = parent
+	}
+}
@@ -0,0 +1,129 @@
+package sifters
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestExtractURLs(t *testing.T) {
+	ev := &nostr.Event{
+		Content: "check https://example.com/a?b=c, and (see http://Foo.example.org/wiki/Go_(lang)). also https://example.com/a?b=c!",
+		Tags: nostr.Tags{
+			{"r", "https://r.example.net"},
+			{"r", "wss://relay.example.com"},
+			{"url", "https://url.example.net/file.png"},
+			{"imeta", "url https://img.example.net/1.png", "m image/png", "fallback https://fallback.example.net/1.png"},
+			{"t", "https://not-a-link-tag.example.com"},
+		},
+	}
+	want := []string{
+		"https://example.com/a?b=c",
+		"http://Foo.example.org/wiki/Go_(lang)",
+		"https://r.example.net",
+		"https://url.example.net/file.png",
+		"https://img.example.net/1.png",
+		"https://fallback.example.net/1.png",
+	}
+
+	if got := extractURLs(ev); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected URLs:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Example.COM", "example.com"},
+		{"www.example.com", "example.com"},
+		{"example.com:8080", "example.com"},
+		{"example.com.", "example.com"},
+		{"ドメイン.例", "xn--eckwd4c7c.xn--fsq"},
+		{"xn--eckwd4c7c.xn--fsq", "xn--eckwd4c7c.xn--fsq"},
+	}
+	for _, tt := range tests {
+		if got, ok := normalizeHost(tt.in); !ok || got != tt.want {
+			t.Fatalf("normalizeHost(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLinkDomainList(t *testing.T) {
+	t.Run("Deny mode rejects events linking to listed domains and their subdomains", func(t *testing.T) {
+		s := LinkDomainList([]string{"spam.example", "WWW.Evil.Example", "ドメイン.例"}, Deny)
+
+		tests := []struct {
+			ev   *nostr.Event
+			want strfrui.Action
+		}{
+			{&nostr.Event{Content: "visit https://spam.example/now"}, strfrui.ActionReject},
+			{&nostr.Event{Content: "visit https://WWW.SPAM.EXAMPLE/now"}, strfrui.ActionReject},
+			{&nostr.Event{Content: "visit https://cdn.spam.example/now"}, strfrui.ActionReject},
+			{&nostr.Event{Content: "visit https://evil.example"}, strfrui.ActionReject},
+			{&nostr.Event{Content: "visit https://xn--eckwd4c7c.xn--fsq/"}, strfrui.ActionReject},
+			{&nostr.Event{Tags: nostr.Tags{{"imeta", "url https://img.spam.example/1.png"}}}, strfrui.ActionReject},
+			{&nostr.Event{Content: "visit https://notspam.example/now"}, strfrui.ActionAccept},
+			{&nostr.Event{Content: "no links"}, strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %+v: %+v", tt.ev, res)
+			}
+		}
+	})
+
+	t.Run("Allow mode accepts events whose links all point to listed domains", func(t *testing.T) {
+		s := LinkDomainList([]string{"nostr.com", "example.com"}, Allow)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"https://nostr.com and https://image.example.com/a.png", strfrui.ActionAccept},
+			{"no links", strfrui.ActionAccept},
+			{"https://nostr.com and https://other.example", strfrui.ActionReject},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+}
+
+func TestMaxLinks(t *testing.T) {
+	s := MaxLinks(2)
+
+	tests := []struct {
+		ev   *nostr.Event
+		want strfrui.Action
+	}{
+		{&nostr.Event{Content: "https://a.example https://b.example"}, strfrui.ActionAccept},
+		{&nostr.Event{Content: "https://a.example https://a.example https://a.example"}, strfrui.ActionAccept},
+		{&nostr.Event{Content: "https://a.example https://b.example", Tags: nostr.Tags{{"r", "https://c.example"}}}, strfrui.ActionReject},
+	}
+	for _, tt := range tests {
+		res, err := s.Sift(inputWithEvent(tt.ev))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != tt.want {
+			t.Fatalf("unexpected result for %+v: %+v", tt.ev, res)
+		}
+		if tt.want == strfrui.ActionReject && res.Msg != "blocked: event has too many links (max: 2)" {
+			t.Fatalf("unexpected message: %q", res.Msg)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package sifters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// MentionLimits describes limits on mentions and hashtags in an event. Zero values mean "unlimited".
+type MentionLimits struct {
+	// Max number of distinct pubkeys mentioned, by "p" tags or by inline "nostr:npub1..." / "nostr:nprofile1..." in the content.
+	MaxMentions int
+
+	// Max number of distinct hashtags, by "t" tags or by inline "#hashtag" in the content. Hashtags are compared case-insensitively.
+	MaxHashtags int
+
+	// Max ratio of inline mentions and hashtags to the whole content (excluding whitespaces), between 0 and 1.
+	// It is for flagging notes whose content is mostly mentions and hashtags.
+	MaxMentionRatio float64
+}
+
+// MentionLimitsForKinds defines [MentionLimits] for specific kinds of events.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+type MentionLimitsForKinds struct {
+	matchKind func(int) bool
+	limits    MentionLimits
+}
+
+// ForKinds makes the [MentionLimits] l be only applied to events of the given set of kinds.
+func (l MentionLimits) ForKinds(kinds ...int) MentionLimitsForKinds {
+	kindSet := utils.SliceToSet(kinds)
+	return l.ForKindsMatching(func(k int) bool {
+		_, ok := kindSet[k]
+		return ok
+	})
+}
+
+// ForKindsMatching makes the [MentionLimits] l be only applied to events of kinds that match the given matcher function.
+//
+// You can use kind matchers such as [KindsAllRegular] here.
+func (l MentionLimits) ForKindsMatching(matchKind func(int) bool) MentionLimitsForKinds {
+	return MentionLimitsForKinds{
+		matchKind: matchKind,
+		limits:    l,
+	}
+}
+
+// MentionFlood makes an event-sifter that checks if the numbers of mentions and hashtags in a Nostr event are within the given limits.
+//
+// This sifter rejects events that exceed the limits with messages prefixed with "blocked:" by default.
+func MentionFlood(limits MentionLimits) *SifterUnit {
+	return MentionFloodPerKind(nil, limits)
+}
+
+// MentionFloodPerKind makes an event-sifter that checks if the numbers of mentions and hashtags in a Nostr event are within limits that vary by kind.
+//
+// The limits for each event kind is specified by the given list of [MentionLimitsForKinds]. Each event is checked against the first limits that match its kind.
+// For event kinds for which limits are not defined, defaultLimits is applied.
+//
+// This sifter rejects events that exceed the limits with messages prefixed with "blocked:" by default.
+func MentionFloodPerKind(limitsPerKind []MentionLimitsForKinds, defaultLimits MentionLimits) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		limits := defaultLimits
+		for _, l := range limitsPerKind {
+			if l.matchKind(input.Event.Kind) {
+				limits = l.limits
+				break
+			}
+		}
+
+		st := countMentions(input.Event)
+		if limits.MaxMentions > 0 && st.mentions > limits.MaxMentions {
+			return rejectWithMsg(fmt.Sprintf("blocked: too many mentions (max: %d)", limits.MaxMentions))
+		}
+		if limits.MaxHashtags > 0 && st.hashtags > limits.MaxHashtags {
+			return rejectWithMsg(fmt.Sprintf("blocked: too many hashtags (max: %d)", limits.MaxHashtags))
+		}
+		if limits.MaxMentionRatio > 0 && st.ratio > limits.MaxMentionRatio {
+			return rejectWithMsg("blocked: content consists mostly of mentions and hashtags")
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("blocked: too many mentions or hashtags")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+var (
+	inlineMentionRegexp = regexp.MustCompile(`nostr:((?:npub|nprofile)1[02-9ac-hj-np-z]+)`)
+	// hashtags must be preceded by the start of the text or a non-word character (to exclude e.g. URL fragments)
+	inlineHashtagRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])#([\p{L}\p{N}_]+)`)
+)
+
+type mentionStats struct {
+	mentions int
+	hashtags int
+	// ratio of inline mentions and hashtags to the content, excluding whitespaces
+	ratio float64
+}
+
+func countMentions(ev *nostr.Event) mentionStats {
+	pubkeys := make(map[string]struct{})
+	hashtags := make(map[string]struct{})
+	for _, tag := range ev.Tags {
+		switch tag.Key() {
+		case "p":
+			pubkeys[tag.Value()] = struct{}{}
+		case "t":
+			hashtags[strings.ToLower(tag.Value())] = struct{}{}
+		}
+	}
+
+	mentionBytes := 0
+	for _, m := range inlineMentionRegexp.FindAllStringSubmatchIndex(ev.Content, -1) {
+		mentionBytes += m[1] - m[0]
+		pubkeys[decodeMentionedPubkey(ev.Content[m[2]:m[3]])] = struct{}{}
+	}
+	for _, m := range inlineHashtagRegexp.FindAllStringSubmatchIndex(ev.Content, -1) {
+		// count from "#", excluding the preceding character
+		mentionBytes += m[3] - m[2] + 1
+		hashtags[strings.ToLower(ev.Content[m[2]:m[3]])] = struct{}{}
+	}
+
+	st := mentionStats{
+		mentions: len(pubkeys),
+		hashtags: len(hashtags),
+	}
+	if total := nonSpaceBytes(ev.Content); total > 0 {
+		st.ratio = float64(mentionBytes) / float64(total)
+	}
+	return st
+}
+
+// decodeMentionedPubkey decodes npub or nprofile to a hex pubkey.
+// If it fails to decode, it returns the input as is so that malformed mentions are still counted.
+func decodeMentionedPubkey(bech32 string) (pubkey string) {
+	// nip19.Decode may panic on malformed nprofile
+	defer func() {
+		if r := recover(); r != nil {
+			pubkey = bech32
+		}
+	}()
+
+	_, v, err := nip19.Decode(bech32)
+	if err != nil {
+		return bech32
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case nostr.ProfilePointer:
+		return v.PublicKey
+	default:
+		return bech32
+	}
+}
+
+func nonSpaceBytes(s string) int {
+	n := 0
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			n += utf8.RuneLen(r)
+		}
+	}
+	return n
+}
@@ -0,0 +1,110 @@
+package sifters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestCountMentions(t *testing.T) {
+	const pk1 = "003ba9b2c5bd8afeed41a4ce362a8b7fc3ab59c25b6a1359cae9093f296dac01"
+	const pk2 = "0000000048ba5812c644dac2f8d53d6ef9b7f143d809a141559e486328ec94af"
+	npub1, _ := nip19.EncodePublicKey(pk1)
+	nprofile2, _ := nip19.EncodeProfile(pk2, []string{"wss://relay.example.com"})
+
+	ev := &nostr.Event{
+		Content: "hi nostr:" + npub1 + " and nostr:" + nprofile2 + " #Nostr #zap https://example.com/#anchor nostr:npub1qqqqqqqq",
+		Tags: nostr.Tags{
+			{"p", pk1},
+			{"t", "nostr"},
+			{"t", "plebchain"},
+		},
+	}
+
+	st := countMentions(ev)
+	// pk1, pk2 and the malformed npub
+	if st.mentions != 3 {
+		t.Errorf("unexpected number of mentions: %d", st.mentions)
+	}
+	// nostr, zap and plebchain
+	if st.hashtags != 3 {
+		t.Errorf("unexpected number of hashtags: %d", st.hashtags)
+	}
+	if st.ratio <= 0.5 || st.ratio >= 1 {
+		t.Errorf("unexpected ratio: %f", st.ratio)
+	}
+}
+
+func TestMentionFlood(t *testing.T) {
+	pTags := func(n int) nostr.Tags {
+		tags := make(nostr.Tags, 0, n)
+		for i := 0; i < n; i++ {
+			tags = append(tags, nostr.Tag{"p", fmt.Sprintf("%064x", i)})
+		}
+		return tags
+	}
+
+	s := MentionFlood(MentionLimits{
+		MaxMentions:     10,
+		MaxHashtags:     3,
+		MaxMentionRatio: 0.8,
+	})
+
+	tests := []struct {
+		name    string
+		ev      *nostr.Event
+		wantMsg string
+	}{
+		{"within limits", &nostr.Event{Content: "hello #nostr #zap", Tags: pTags(10)}, ""},
+		{"too many p tags", &nostr.Event{Content: "hello", Tags: pTags(11)}, "blocked: too many mentions (max: 10)"},
+		{"too many hashtags", &nostr.Event{Content: "#a #b #c #d are hashtags"}, "blocked: too many hashtags (max: 3)"},
+		{"duplicated hashtags are counted once", &nostr.Event{Content: "#a #A #b #c are hashtags", Tags: nostr.Tags{{"t", "a"}}}, ""},
+		{"mostly hashtags", &nostr.Event{Content: "#bitcoin #nostr hi"}, "blocked: content consists mostly of mentions and hashtags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantMsg == "" {
+				if res.Action != strfrui.ActionAccept {
+					t.Fatalf("unexpected result: %+v", res)
+				}
+				return
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		})
+	}
+}
+
+func TestMentionFloodPerKind(t *testing.T) {
+	s := MentionFloodPerKind(
+		[]MentionLimitsForKinds{
+			MentionLimits{MaxHashtags: 0}.ForKinds(30023),
+		},
+		MentionLimits{MaxHashtags: 1},
+	)
+
+	tests := []struct {
+		kind int
+		want strfrui.Action
+	}{
+		{1, strfrui.ActionReject},
+		{30023, strfrui.ActionAccept},
+	}
+	for _, tt := range tests {
+		res, err := s.Sift(inputWithEvent(&nostr.Event{Kind: tt.kind, Content: "#a #b"}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != tt.want {
+			t.Fatalf("unexpected result for kind %d: %+v", tt.kind, res)
+		}
+	}
+}
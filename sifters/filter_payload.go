@@ -0,0 +1,58 @@
+package sifters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+var (
+	dataURIRegexp = regexp.MustCompile(`(?i)\bdata:[^,\s]{0,256},(\S*)`)
+	// base64 (including URL-safe variant) blobs, possibly wrapped into multiple lines
+	base64BlobRegexp = regexp.MustCompile(`[A-Za-z0-9+/_-]+(?:\r?\n[A-Za-z0-9+/_-]+)*={0,2}`)
+	// URLs and Nostr entities, whose long paths, queries and bech32 data look like base64 blobs
+	nonPayloadRegexp = regexp.MustCompile(`(?i)(?:https?|wss?)://\S+|\b(?:nostr:)?(?:npub|nsec|nprofile|note|nevent|naddr|nrelay)1[02-9ac-hj-np-z]+`)
+)
+
+// EmbeddedPayloads makes an event-sifter that checks if a content of a Nostr event has no inline payloads larger than maxBytes,
+// such as media embedded as [data URIs] or base64 strings.
+//
+// The size of a payload is measured in its encoded form.
+// To avoid misdetecting normal texts, only strings without whitespaces (except line breaks) that have uppercase letters, lowercase letters and digits are considered base64 blobs.
+// So hex strings are not considered base64 blobs. URLs and Nostr entities (e.g. "nostr:nevent1...") are not considered either.
+//
+// This sifter rejects events with large payloads with messages prefixed with "blocked:" by default.
+//
+// [data URIs]: https://developer.mozilla.org/en-US/docs/Web/HTTP/Basics_of_HTTP/Data_URLs
+func EmbeddedPayloads(maxBytes int) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		content := input.Event.Content
+		if len(content) <= maxBytes {
+			// no payload can exceed the limit
+			return inputMatch, nil
+		}
+
+		for _, m := range dataURIRegexp.FindAllStringSubmatchIndex(content, -1) {
+			if m[3]-m[2] > maxBytes {
+				return rejectWithMsg(fmt.Sprintf("blocked: content has an embedded data URI larger than %d bytes", maxBytes))
+			}
+		}
+		for _, blob := range base64BlobRegexp.FindAllString(nonPayloadRegexp.ReplaceAllString(content, " "), -1) {
+			if len(blob) > maxBytes && looksLikeBase64(blob) {
+				return rejectWithMsg(fmt.Sprintf("blocked: content has an embedded base64 payload larger than %d bytes", maxBytes))
+			}
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("blocked: content has a large embedded payload")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+func looksLikeBase64(s string) bool {
+	return strings.ContainsAny(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") &&
+		strings.ContainsAny(s, "abcdefghijklmnopqrstuvwxyz") &&
+		strings.ContainsAny(s, "0123456789")
+}
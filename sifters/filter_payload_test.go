@@ -0,0 +1,63 @@
+package sifters
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+)
+
+func TestEmbeddedPayloads(t *testing.T) {
+	randBytes := func(n int) []byte {
+		b := make([]byte, n)
+		if _, err := rand.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	// make sure that the blob contains uppercase letters, lowercase letters and digits
+	b64 := "Aa0" + base64.StdEncoding.EncodeToString(randBytes(300))
+	wrapped := b64[:76] + "\n" + b64[76:152] + "\n" + b64[152:]
+
+	s := EmbeddedPayloads(200)
+
+	tests := []struct {
+		name    string
+		content string
+		wantMsg string
+	}{
+		{"short text", "hello", ""},
+		{"long normal text", strings.Repeat("The quick brown fox jumps over the lazy dog 123. ", 20), ""},
+		{"long hex", hex.EncodeToString(randBytes(200)), ""},
+		{"long bech32 entity", "nostr:naddr1" + strings.Repeat("qpzry9x8gf2tvdw0s3jn54khce6mua7l", 10), ""},
+		{"long URL", "see https://example.com/" + strings.Repeat("Path0To1Resource", 15) + "?q=" + strings.Repeat("QueryValue2", 10) + " !", ""},
+		{"long relay URL", "wss://relay.example.com/" + strings.Repeat("Aa0", 100), ""},
+		{"data: within a word", "metadata:text," + strings.Repeat("y", 300), ""},
+		{"small data URI", "look: data:image/png;base64,iVBORw0KGgo=", ""},
+		{"large data URI", "look: data:image/png;base64," + b64 + " nice", "blocked: content has an embedded data URI larger than 200 bytes"},
+		{"large percent-encoded data URI", "data:text/plain," + strings.Repeat("%20", 100), "blocked: content has an embedded data URI larger than 200 bytes"},
+		{"large base64 blob disguised as a Nostr URI", "nostr:" + b64, "blocked: content has an embedded base64 payload larger than 200 bytes"},
+		{"large base64 blob", "payload: " + b64, "blocked: content has an embedded base64 payload larger than 200 bytes"},
+		{"large wrapped base64 blob", "payload:\n" + wrapped, "blocked: content has an embedded base64 payload larger than 200 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantMsg == "" {
+				if res.Action != strfrui.ActionAccept {
+					t.Fatalf("unexpected result: %+v", res)
+				}
+				return
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package sifters
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/jiftechnify/strfrui"
 	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+	"github.com/nbd-wtf/go-nostr"
 )
 
 var nibbleToLzs = map[rune]uint{
@@ -33,7 +36,8 @@ func leadingZerosOfEventID(id string) (uint, error) {
 // PoWMinDifficulty makes an event-sifter that checks if the Proof of Work (PoW) difficulty of a Nostr event
 // is higher than or equal to the given minimum difficulty.
 //
-// About PoW for Nostr events, see [NIP-13]. Note that this sifter doesn't check if the "target difficulty" declared by the nonce tag is achieved.
+// About PoW for Nostr events, see [NIP-13]. Note that this sifter doesn't check the "target difficulty" committed by the nonce tag.
+// If you want to check it as well, use [PoWMinDifficultyCommitted] instead.
 //
 // [NIP-13]: https://github.com/nostr-protocol/nips/blob/master/13.md
 func PoWMinDifficulty(minDifficulty uint) *SifterUnit {
@@ -47,3 +51,134 @@ func PoWMinDifficulty(minDifficulty uint) *SifterUnit {
 	defaultRejFn := internal.RejectWithMsg(fmt.Sprintf("pow: difficulty is less than %d", minDifficulty))
 	return newSifterUnit(matchInput, Allow, defaultRejFn)
 }
+
+// PoWMinDifficultyCommitted makes an event-sifter that checks if both the Proof of Work (PoW) difficulty of a Nostr event
+// and the "target difficulty" committed by the nonce tag of the event are higher than or equal to the given minimum difficulty.
+//
+// As per [NIP-13], the nonce tag should be in the form of ["nonce", "<nonce>", "<target difficulty>"].
+// Committing to the target difficulty prevents users who got lucky with unintended low-difficulty nonce from passing the check.
+// This sifter rejects events without a valid nonce tag.
+//
+// [NIP-13]: https://github.com/nostr-protocol/nips/blob/master/13.md
+func PoWMinDifficultyCommitted(minDifficulty uint) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		return checkPoW(input.Event, minDifficulty, true)
+	}
+	defaultRejFn := internal.RejectWithMsg(fmt.Sprintf("pow: difficulty is less than %d", minDifficulty))
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+// checkPoW checks if the PoW difficulty of the event is higher than or equal to minDifficulty.
+// If requireCommitment is true, it also checks the target difficulty committed by the nonce tag.
+func checkPoW(ev *nostr.Event, minDifficulty uint, requireCommitment bool) (inputMatchResult, error) {
+	if requireCommitment {
+		nonceTag := ev.Tags.GetFirst([]string{"nonce", ""})
+		if nonceTag == nil || len(*nonceTag) < 3 {
+			return rejectWithMsg("pow: event must have a nonce tag committing to target difficulty")
+		}
+		target, err := strconv.ParseUint((*nonceTag)[2], 10, 0)
+		if err != nil {
+			return rejectWithMsg("pow: target difficulty in the nonce tag is malformed")
+		}
+		if uint(target) < minDifficulty {
+			return rejectWithMsg(fmt.Sprintf("pow: committed target difficulty is less than %d", minDifficulty))
+		}
+	}
+
+	difficulty, err := leadingZerosOfEventID(ev.ID)
+	if err != nil {
+		return inputAlwaysReject, err
+	}
+	if difficulty < minDifficulty {
+		return rejectWithMsg(fmt.Sprintf("pow: difficulty is less than %d", minDifficulty))
+	}
+	return inputMatch, nil
+}
+
+// PoWRule defines a minimum PoW difficulty for events that satisfy conditions of the rule.
+// Use it with [PoWMinDifficultyByRules].
+//
+// You can concisely create a rule by [PoWDifficulty] and narrow down target events with its methods, such as [PoWRule.ForKinds] and [PoWRule.FromSources].
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+type PoWRule struct {
+	minDifficulty     uint
+	requireCommitment bool
+	matchKind         func(int) bool
+	sourceTypes       map[strfrui.SourceType]struct{}
+	cond              func(*strfrui.Input) bool
+}
+
+// PoWDifficulty creates a [PoWRule] that requires the given minimum difficulty to all events.
+func PoWDifficulty(minDifficulty uint) PoWRule {
+	return PoWRule{minDifficulty: minDifficulty}
+}
+
+// ForKinds makes the [PoWRule] r be only applied to events of the given set of kinds.
+func (r PoWRule) ForKinds(kinds ...int) PoWRule {
+	kindSet := utils.SliceToSet(kinds)
+	r.matchKind = func(k int) bool {
+		_, ok := kindSet[k]
+		return ok
+	}
+	return r
+}
+
+// ForKindsMatching makes the [PoWRule] r be only applied to events of kinds that match the given matcher function.
+//
+// You can use kind matchers such as [KindsAllEphemeral] and [KindsAllRegular] here.
+func (r PoWRule) ForKindsMatching(matchKind func(int) bool) PoWRule {
+	r.matchKind = matchKind
+	return r
+}
+
+// FromSources makes the [PoWRule] r be only applied to events from the given set of source types.
+func (r PoWRule) FromSources(sourceTypes ...strfrui.SourceType) PoWRule {
+	r.sourceTypes = utils.SliceToSet(sourceTypes)
+	return r
+}
+
+// When makes the [PoWRule] r be only applied to inputs that satisfy the given condition,
+// in addition to conditions on kinds and source types (e.g. only to events from source IP addresses not in a whitelist).
+func (r PoWRule) When(cond func(*strfrui.Input) bool) PoWRule {
+	r.cond = cond
+	return r
+}
+
+// RequireCommitment makes the [PoWRule] r also require the target difficulty committed by the nonce tag to be higher than or equal to the minimum difficulty.
+// See [PoWMinDifficultyCommitted] for details.
+func (r PoWRule) RequireCommitment() PoWRule {
+	r.requireCommitment = true
+	return r
+}
+
+func (r PoWRule) matches(input *strfrui.Input) bool {
+	if r.matchKind != nil && !r.matchKind(input.Event.Kind) {
+		return false
+	}
+	if r.sourceTypes != nil {
+		if _, ok := r.sourceTypes[input.SourceType]; !ok {
+			return false
+		}
+	}
+	return r.cond == nil || r.cond(input)
+}
+
+// PoWMinDifficultyByRules makes an event-sifter that checks if the Proof of Work (PoW) difficulty of a Nostr event
+// is higher than or equal to the minimum difficulty defined by the given list of [PoWRule]s.
+//
+// Each event is checked against the first rule whose conditions are satisfied by the event.
+// Events that don't satisfy conditions of any rule are accepted.
+// To require some difficulty to such events, put a rule without conditions (e.g. PoWDifficulty(10)) at the end of the list.
+func PoWMinDifficultyByRules(rules []PoWRule) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		for _, r := range rules {
+			if r.matches(input) {
+				return checkPoW(input.Event, r.minDifficulty, r.requireCommitment)
+			}
+		}
+		return inputAlwaysAccept, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("pow: difficulty is not enough")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
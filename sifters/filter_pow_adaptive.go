@@ -0,0 +1,221 @@
+package sifters
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+// LoadMeter measures the recent load of a relay: the rate of incoming events and the ratio of rejected events.
+// Measurements are exponentially decaying averages, so they rise quickly during spam waves and decay back when the waves end.
+//
+// To measure the load, wrap a sifter with [LoadMeter.Observe]. Measurements can be used to drive an [AdaptivePoWSifter].
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [NewLoadMeter] function to construct an instance of LoadMeter.
+type LoadMeter struct {
+	mu         sync.Mutex
+	halfLife   time.Duration
+	events     float64 // exponentially decayed count of events
+	rejects    float64 // exponentially decayed count of rejected events
+	lastUpdate time.Time
+}
+
+// NewLoadMeter creates a [LoadMeter]. halfLife specifies how fast past events are "forgotten" (defaults to 1 minute).
+func NewLoadMeter(halfLife time.Duration) *LoadMeter {
+	if halfLife <= 0 {
+		halfLife = time.Minute
+	}
+	return &LoadMeter{
+		halfLife: halfLife,
+	}
+}
+
+// Observe wraps the sifter so that the meter observes all inputs to the sifter and their results.
+func (m *LoadMeter) Observe(s strfrui.Sifter) strfrui.Sifter {
+	return strfrui.SifterFunc(func(input *strfrui.Input) (*strfrui.Result, error) {
+		res, err := s.Sift(input)
+		m.record(err != nil || res.Action != strfrui.ActionAccept)
+		return res, err
+	})
+}
+
+// EventRate returns the recent rate of incoming events, in events per second.
+func (m *LoadMeter) EventRate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decay(clock.now())
+	return m.events * math.Ln2 / m.halfLife.Seconds()
+}
+
+// RejectRatio returns the recent ratio of rejected events to all events, between 0 and 1.
+func (m *LoadMeter) RejectRatio() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decay(clock.now())
+	if m.events == 0 {
+		return 0
+	}
+	return m.rejects / m.events
+}
+
+func (m *LoadMeter) record(rejected bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decay(clock.now())
+	m.events++
+	if rejected {
+		m.rejects++
+	}
+}
+
+// decay applies exponential decay to counts as time goes by. Caller must hold m.mu.
+func (m *LoadMeter) decay(now time.Time) {
+	if !m.lastUpdate.IsZero() && now.After(m.lastUpdate) {
+		f := math.Exp2(-now.Sub(m.lastUpdate).Seconds() / m.halfLife.Seconds())
+		m.events *= f
+		m.rejects *= f
+	}
+	if now.After(m.lastUpdate) {
+		m.lastUpdate = now
+	}
+}
+
+// AdaptivePoWConfig is a configuration of [AdaptivePoWSifter].
+type AdaptivePoWConfig struct {
+	// Minimum PoW difficulty required under normal load.
+	BaseDifficulty uint
+
+	// Upper bound of the required difficulty.
+	MaxDifficulty uint
+
+	// The load at and below which BaseDifficulty is required.
+	LoadThreshold float64
+
+	// The difficulty added each time the load doubles over LoadThreshold. Defaults to 1.
+	StepPerDoubling uint
+}
+
+// AdaptivePoWSifter is an event-sifter that checks if the Proof of Work (PoW) difficulty of a Nostr event
+// is higher than or equal to the minimum difficulty that automatically scales with the relay load.
+//
+// The required difficulty is BaseDifficulty while the load is below twice LoadThreshold,
+// and increases by StepPerDoubling each time the load doubles over the threshold, up to MaxDifficulty.
+// For example, the difficulty is BaseDifficulty + StepPerDoubling while the load is between 2x and 4x of the threshold.
+// It decays back to BaseDifficulty as the load decreases.
+//
+// AdaptivePoWSifter rejects with message: "pow: difficulty is less than <current required difficulty>" by default,
+// so that clients know the difficulty to mine for.
+// If you want to customize rejection behavior,
+// call [AdaptivePoWSifter.RejectWithMsg], [AdaptivePoWSifter.RejectWithMsgFromInput] or [AdaptivePoWSifter.ShadowReject] methods on it.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [AdaptivePoW] function to construct an instance of AdaptivePoWSifter.
+type AdaptivePoWSifter struct {
+	unit *SifterUnit
+	cfg  AdaptivePoWConfig
+	load func() float64
+
+	mu                 sync.Mutex
+	lastDifficulty     uint
+	onDifficultyChange func(old, new uint)
+}
+
+func (s *AdaptivePoWSifter) Sift(input *strfrui.Input) (*strfrui.Result, error) {
+	return s.unit.Sift(input)
+}
+
+// CurrentDifficulty returns the currently required PoW difficulty.
+func (s *AdaptivePoWSifter) CurrentDifficulty() uint {
+	return difficultyForLoad(s.cfg, s.load())
+}
+
+// OnDifficultyChange registers a callback that is called with the old and new difficulty every time the sifter notices a change of the required difficulty.
+// It is useful for logging changes or reporting the difficulty to metrics.
+func (s *AdaptivePoWSifter) OnDifficultyChange(f func(old, new uint)) *AdaptivePoWSifter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onDifficultyChange = f
+	return s
+}
+
+// difficulty returns the currently required PoW difficulty, and notifies the change of it if any.
+func (s *AdaptivePoWSifter) difficulty() uint {
+	d := s.CurrentDifficulty()
+
+	s.mu.Lock()
+	old := s.lastDifficulty
+	s.lastDifficulty = d
+	onChange := s.onDifficultyChange
+	s.mu.Unlock()
+
+	if d != old && onChange != nil {
+		onChange(old, d)
+	}
+	return d
+}
+
+// ShadowReject sets the sifter's rejection behavior to "shadow-reject",
+// which pretend to accept the input but actually reject it.
+func (s *AdaptivePoWSifter) ShadowReject() *AdaptivePoWSifter {
+	s.unit.ShadowReject()
+	return s
+}
+
+// RejectWithMsg makes the sifter reject the input with the given message.
+func (s *AdaptivePoWSifter) RejectWithMsg(msg string) *AdaptivePoWSifter {
+	s.unit.RejectWithMsg(msg)
+	return s
+}
+
+// RejectWithMsgFromInput makes the sifter reject the input with the message derived from the input by the given function.
+func (s *AdaptivePoWSifter) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string) *AdaptivePoWSifter {
+	s.unit.RejectWithMsgFromInput(getMsg)
+	return s
+}
+
+// AdaptivePoW makes an [AdaptivePoWSifter] with the given configuration.
+//
+// load is a function that returns the current load of the relay.
+// Typically, it is [LoadMeter.EventRate] (events/sec) or [LoadMeter.RejectRatio] of a [LoadMeter] that observes the whole sifter.
+// Note that if the meter observes this sifter itself and load is the reject ratio, rejections by this sifter also raise the difficulty.
+//
+// For more details about the behavior of the sifter, see the doc of [AdaptivePoWSifter] type.
+func AdaptivePoW(cfg AdaptivePoWConfig, load func() float64) *AdaptivePoWSifter {
+	if cfg.StepPerDoubling == 0 {
+		cfg.StepPerDoubling = 1
+	}
+	if cfg.MaxDifficulty < cfg.BaseDifficulty {
+		cfg.MaxDifficulty = cfg.BaseDifficulty
+	}
+
+	s := &AdaptivePoWSifter{
+		cfg:            cfg,
+		load:           load,
+		lastDifficulty: cfg.BaseDifficulty,
+	}
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		return checkPoW(input.Event, s.difficulty(), false)
+	}
+	s.unit = newSifterUnit(matchInput, Allow, internal.RejectWithMsg("pow: difficulty is not enough"))
+	return s
+}
+
+func difficultyForLoad(cfg AdaptivePoWConfig, load float64) uint {
+	if cfg.LoadThreshold <= 0 || load <= cfg.LoadThreshold {
+		return cfg.BaseDifficulty
+	}
+	doublings := math.Floor(math.Log2(load / cfg.LoadThreshold))
+	d := float64(cfg.BaseDifficulty) + doublings*float64(cfg.StepPerDoubling)
+	if d >= float64(cfg.MaxDifficulty) {
+		return cfg.MaxDifficulty
+	}
+	return uint(d)
+}
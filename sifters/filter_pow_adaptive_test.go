@@ -0,0 +1,144 @@
+package sifters
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAdaptivePoW(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock.setFake(now)
+	t.Cleanup(func() {
+		clock.reset()
+	})
+
+	const (
+		id0  = "afd8949610b42451fb99675ace8fa222d436db48643b69241b00954c8a89f4c7" // diff: 0
+		id20 = "00000ab6a5f21ac2c8d87c8c6cd6e6c4b4c22b5ab8e9ad35a3a1dd57b2f17c91" // diff: 20
+		id32 = "0000000085884ec468245df4cc0e07657b2dccddd2245b318528bcb41b1d8f72" // diff: 32
+	)
+
+	meter := NewLoadMeter(10 * time.Second)
+	pow := AdaptivePoW(AdaptivePoWConfig{
+		BaseDifficulty:  10,
+		MaxDifficulty:   30,
+		LoadThreshold:   1, // 1 event/sec
+		StepPerDoubling: 4,
+	}, meter.EventRate)
+	s := meter.Observe(pow)
+
+	changes := make([][2]uint, 0)
+	pow.OnDifficultyChange(func(old, new uint) {
+		changes = append(changes, [2]uint{old, new})
+	})
+
+	sift := func(id string) *strfrui.Result {
+		t.Helper()
+		res, err := s.Sift(inputWithEvent(&nostr.Event{ID: id}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res
+	}
+
+	// normal load
+	if d := pow.CurrentDifficulty(); d != 10 {
+		t.Fatalf("unexpected difficulty: %d", d)
+	}
+	if res := sift(id20); res.Action != strfrui.ActionAccept {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res := sift(id0); res.Action != strfrui.ActionReject || res.Msg != "pow: difficulty is less than 10" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// spam wave: 130 events in an instant (≒ 9.0 events/sec, 3 doublings over the threshold)
+	for i := 0; i < 128; i++ {
+		sift(id0)
+	}
+	if d := pow.CurrentDifficulty(); d != 22 {
+		t.Fatalf("unexpected difficulty: %d", d)
+	}
+	if res := sift(id20); res.Action != strfrui.ActionReject || res.Msg != "pow: difficulty is less than 22" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res := sift(id32); res.Action != strfrui.ActionAccept {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// the wave ends
+	clock.setFake(now.Add(100 * time.Second))
+	if d := pow.CurrentDifficulty(); d != 10 {
+		t.Fatalf("unexpected difficulty: %d", d)
+	}
+	if res := sift(id20); res.Action != strfrui.ActionAccept {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// changes are notified only once per change
+	want := [][2]uint{{10, 14}, {14, 18}, {18, 22}, {22, 10}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected difficulty changes: %v", changes)
+	}
+}
+
+func TestDifficultyForLoad(t *testing.T) {
+	cfg := AdaptivePoWConfig{
+		BaseDifficulty:  10,
+		MaxDifficulty:   20,
+		LoadThreshold:   1,
+		StepPerDoubling: 4,
+	}
+	tests := []struct {
+		load float64
+		want uint
+	}{
+		{0, 10},
+		{1, 10},
+		{1.5, 10},
+		{2, 14},
+		{3.9, 14},
+		{4, 18},
+		{8, 20}, // capped by MaxDifficulty
+	}
+	for _, tt := range tests {
+		if got := difficultyForLoad(cfg, tt.load); got != tt.want {
+			t.Errorf("difficultyForLoad(%v) = %d, want %d", tt.load, got, tt.want)
+		}
+	}
+}
+
+func TestLoadMeter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock.setFake(now)
+	t.Cleanup(func() {
+		clock.reset()
+	})
+
+	m := NewLoadMeter(10 * time.Second)
+	s := m.Observe(KindList([]int{1}, Allow))
+
+	for i := 0; i < 3; i++ {
+		_, _ = s.Sift(inputWithEvent(&nostr.Event{Kind: 1}))
+	}
+	_, _ = s.Sift(inputWithEvent(&nostr.Event{Kind: 7}))
+
+	if r := m.RejectRatio(); r != 0.25 {
+		t.Fatalf("unexpected reject ratio: %v", r)
+	}
+
+	// counts are halved after the half-life, but the ratio is kept
+	r1 := m.EventRate()
+	clock.setFake(now.Add(10 * time.Second))
+	r2 := m.EventRate()
+	if r2 < r1/2-1e-9 || r2 > r1/2+1e-9 {
+		t.Fatalf("unexpected event rate: %v (before: %v)", r2, r1)
+	}
+	if r := m.RejectRatio(); r != 0.25 {
+		t.Fatalf("unexpected reject ratio: %v", r)
+	}
+}
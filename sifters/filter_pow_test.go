@@ -46,3 +46,120 @@ func TestPoWMinDifficulty(t *testing.T) {
 		}
 	})
 }
+
+func TestPoWMinDifficultyCommitted(t *testing.T) {
+	const (
+		id33 = "0000000048ba5812c644dac2f8d53d6ef9b7f143d809a141559e486328ec94af" // diff: 33
+		id32 = "0000000085884ec468245df4cc0e07657b2dccddd2245b318528bcb41b1d8f72" // diff: 32
+	)
+
+	t.Run("accepts if both PoW difficulty and committed target are greater than or equal to the threshold", func(t *testing.T) {
+		s := PoWMinDifficultyCommitted(33)
+
+		evs := []*nostr.Event{
+			{ID: id33, Tags: nostr.Tags{{"nonce", "12345", "33"}}},
+			{ID: id33, Tags: nostr.Tags{{"nonce", "12345", "40"}}},
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+
+	t.Run("rejects if nonce tag is missing or invalid", func(t *testing.T) {
+		s := PoWMinDifficultyCommitted(33)
+
+		tests := []struct {
+			ev      *nostr.Event
+			wantMsg string
+		}{
+			{
+				ev:      &nostr.Event{ID: id33},
+				wantMsg: "pow: event must have a nonce tag committing to target difficulty",
+			},
+			{
+				ev:      &nostr.Event{ID: id33, Tags: nostr.Tags{{"nonce", "12345"}}},
+				wantMsg: "pow: event must have a nonce tag committing to target difficulty",
+			},
+			{
+				ev:      &nostr.Event{ID: id33, Tags: nostr.Tags{{"nonce", "12345", "high"}}},
+				wantMsg: "pow: target difficulty in the nonce tag is malformed",
+			},
+			{
+				// got lucky: actual difficulty is enough, but committed target is low
+				ev:      &nostr.Event{ID: id33, Tags: nostr.Tags{{"nonce", "12345", "10"}}},
+				wantMsg: "pow: committed target difficulty is less than 33",
+			},
+			{
+				ev:      &nostr.Event{ID: id32, Tags: nostr.Tags{{"nonce", "12345", "33"}}},
+				wantMsg: "pow: difficulty is less than 33",
+			},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+}
+
+func TestPoWMinDifficultyByRules(t *testing.T) {
+	const (
+		id0  = "afd8949610b42451fb99675ace8fa222d436db48643b69241b00954c8a89f4c7" // diff: 0
+		id20 = "00000ab6a5f21ac2c8d87c8c6cd6e6c4b4c22b5ab8e9ad35a3a1dd57b2f17c91" // diff: 20
+		id32 = "0000000085884ec468245df4cc0e07657b2dccddd2245b318528bcb41b1d8f72" // diff: 32
+	)
+
+	s := PoWMinDifficultyByRules([]PoWRule{
+		PoWDifficulty(0).ForKinds(7),
+		PoWDifficulty(0).FromSources(strfrui.SourceTypeImport),
+		PoWDifficulty(20).ForKinds(1).FromSources(strfrui.SourceTypeIP4, strfrui.SourceTypeIP6).
+			When(func(i *strfrui.Input) bool { return i.SourceInfo != "127.0.0.1" }),
+		PoWDifficulty(30).ForKindsMatching(KindsAllReplaceable),
+	})
+
+	input := func(id string, kind int, st strfrui.SourceType, si string) *strfrui.Input {
+		return &strfrui.Input{
+			SourceType: st,
+			SourceInfo: si,
+			Event:      &nostr.Event{ID: id, Kind: kind},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		input   *strfrui.Input
+		want    strfrui.Action
+		wantMsg string
+	}{
+		{"reaction", input(id0, 7, strfrui.SourceTypeIP4, "192.168.1.1"), strfrui.ActionAccept, ""},
+		{"import", input(id0, 1, strfrui.SourceTypeImport, ""), strfrui.ActionAccept, ""},
+		{"kind 1 from end-user, enough", input(id20, 1, strfrui.SourceTypeIP4, "192.168.1.1"), strfrui.ActionAccept, ""},
+		{"kind 1 from end-user, not enough", input(id0, 1, strfrui.SourceTypeIP6, "2001:db8::1"), strfrui.ActionReject, "pow: difficulty is less than 20"},
+		{"kind 1 from local", input(id0, 1, strfrui.SourceTypeIP4, "127.0.0.1"), strfrui.ActionAccept, ""},
+		{"kind 1 from stream", input(id0, 1, strfrui.SourceTypeStream, "wss://relay.example.com"), strfrui.ActionAccept, ""},
+		{"replaceable, enough", input(id32, 0, strfrui.SourceTypeIP4, "192.168.1.1"), strfrui.ActionAccept, ""},
+		{"replaceable, not enough", input(id20, 30000, strfrui.SourceTypeStream, "wss://relay.example.com"), strfrui.ActionReject, "pow: difficulty is less than 30"},
+	}
+
+	for _, tt := range tests {
+		res, err := s.Sift(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if res.Action != tt.want || res.Msg != tt.wantMsg {
+			t.Fatalf("%s: unexpected result: %+v", tt.name, res)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sifters
+
+import (
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+// AuthResolver resolves the pubkey authenticated via [NIP-42] on the connection which an input came from.
+//
+// As of now, event-sifter plugins can't see the authentication state of connections,
+// so there is no built-in implementation. This interface is a hook for inputs that carry such information in the future.
+//
+// [NIP-42]: https://github.com/nostr-protocol/nips/blob/master/42.md
+type AuthResolver interface {
+	// AuthedPubKey returns the authenticated pubkey. ok is false if the connection is not authenticated.
+	AuthedPubKey(input *strfrui.Input) (pubkey string, ok bool)
+}
+
+// AuthResolverFunc is an adapter to allow the use of functions as an AuthResolver.
+type AuthResolverFunc func(input *strfrui.Input) (pubkey string, ok bool)
+
+func (f AuthResolverFunc) AuthedPubKey(input *strfrui.Input) (string, bool) {
+	return f(input)
+}
+
+func isProtectedEvent(input *strfrui.Input) bool {
+	for _, tag := range input.Event.Tags {
+		if len(tag) >= 1 && tag[0] == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedEvents makes an event-sifter that enforces the rule of "protected events" defined in [NIP-70]:
+// events with the ["-"] tag must only be accepted from their author over an authenticated connection.
+//
+// Protected events not from end-users (i.e. events imported via "strfry import" or from other relays) are always rejected,
+// since they are obviously not published by their author directly.
+//
+// For protected events from end-users, if auth is nil, this sifter accepts them because it can't know who is authenticated.
+// Otherwise, this sifter accepts them only if the authenticated pubkey resolved by auth is equal to the author of the event.
+//
+// Events without the ["-"] tag are always accepted.
+//
+// [NIP-70]: https://github.com/nostr-protocol/nips/blob/master/70.md
+func ProtectedEvents(auth AuthResolver) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		if !isProtectedEvent(input) {
+			return inputAlwaysAccept, nil
+		}
+		if !input.SourceType.IsEndUser() {
+			return rejectWithMsg("blocked: protected events must be published by their author")
+		}
+		if auth == nil {
+			return inputMatch, nil
+		}
+		authed, ok := auth.AuthedPubKey(input)
+		if !ok {
+			return rejectWithMsg("auth-required: this event may only be published by its author")
+		}
+		if authed != input.Event.PubKey {
+			return rejectWithMsg("restricted: this event may only be published by its author")
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("blocked: protected events must be published by their author")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
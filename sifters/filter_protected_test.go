@@ -0,0 +1,87 @@
+package sifters
+
+import (
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestProtectedEvents(t *testing.T) {
+	input := func(st strfrui.SourceType, protected bool) *strfrui.Input {
+		ev := &nostr.Event{PubKey: "author", Tags: nostr.Tags{{"t", "nostr"}}}
+		if protected {
+			ev.Tags = append(ev.Tags, nostr.Tag{"-"})
+		}
+		return &strfrui.Input{
+			SourceType: st,
+			Event:      ev,
+		}
+	}
+
+	t.Run("without auth resolver", func(t *testing.T) {
+		s := ProtectedEvents(nil)
+
+		tests := []struct {
+			name    string
+			input   *strfrui.Input
+			want    strfrui.Action
+			wantMsg string
+		}{
+			{"unprotected, from end-user", input(strfrui.SourceTypeIP4, false), strfrui.ActionAccept, ""},
+			{"unprotected, from stream", input(strfrui.SourceTypeStream, false), strfrui.ActionAccept, ""},
+			{"protected, from end-user", input(strfrui.SourceTypeIP6, true), strfrui.ActionAccept, ""},
+			{"protected, from stream", input(strfrui.SourceTypeStream, true), strfrui.ActionReject, "blocked: protected events must be published by their author"},
+			{"protected, from sync", input(strfrui.SourceTypeSync, true), strfrui.ActionReject, "blocked: protected events must be published by their author"},
+			{"protected, from import", input(strfrui.SourceTypeImport, true), strfrui.ActionReject, "blocked: protected events must be published by their author"},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(tt.input)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+			if res.Action != tt.want || res.Msg != tt.wantMsg {
+				t.Fatalf("%s: unexpected result: %+v", tt.name, res)
+			}
+		}
+	})
+
+	t.Run("with auth resolver", func(t *testing.T) {
+		authed := map[string]string{
+			"192.168.1.1": "author",
+			"192.168.1.2": "someone",
+		}
+		s := ProtectedEvents(AuthResolverFunc(func(input *strfrui.Input) (string, bool) {
+			pk, ok := authed[input.SourceInfo]
+			return pk, ok
+		}))
+
+		withAddr := func(in *strfrui.Input, addr string) *strfrui.Input {
+			in.SourceInfo = addr
+			return in
+		}
+
+		tests := []struct {
+			name    string
+			input   *strfrui.Input
+			want    strfrui.Action
+			wantMsg string
+		}{
+			{"authed as author", withAddr(input(strfrui.SourceTypeIP4, true), "192.168.1.1"), strfrui.ActionAccept, ""},
+			{"authed as someone else", withAddr(input(strfrui.SourceTypeIP4, true), "192.168.1.2"), strfrui.ActionReject, "restricted: this event may only be published by its author"},
+			{"not authed", withAddr(input(strfrui.SourceTypeIP4, true), "192.168.1.3"), strfrui.ActionReject, "auth-required: this event may only be published by its author"},
+			{"not authed, unprotected", withAddr(input(strfrui.SourceTypeIP4, false), "192.168.1.3"), strfrui.ActionAccept, ""},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(tt.input)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+			if res.Action != tt.want || res.Msg != tt.wantMsg {
+				t.Fatalf("%s: unexpected result: %+v", tt.name, res)
+			}
+		}
+	})
+}
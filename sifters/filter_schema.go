@@ -0,0 +1,173 @@
+package sifters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindValidator validates a Nostr event of specific kinds.
+// It should return an error describing the problem if the event is invalid.
+type KindValidator func(*nostr.Event) error
+
+type kindSchema struct {
+	matchKind func(int) bool
+	validate  KindValidator
+}
+
+// KindSchemas is a registry of [KindValidator]s per event kind. Use it with [ConformsToKindSchemas].
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [NewKindSchemas] or [DefaultKindSchemas] function to construct an instance of KindSchemas.
+type KindSchemas struct {
+	schemas []kindSchema
+}
+
+// NewKindSchemas creates an empty [KindSchemas].
+func NewKindSchemas() *KindSchemas {
+	return &KindSchemas{}
+}
+
+// DefaultKindSchemas creates a [KindSchemas] with built-in validators for well-known event kinds:
+//
+//   - kind 0 (metadata): content must be a JSON object, and well-known fields (name, about, picture etc.) must be strings if present.
+//   - kind 3 (contact list) and kind 10000-19999 (lists): values of "p" and "e" tags must be 64-char lowercase hex.
+//   - kind 10002 (relay list): values of "r" tags must be relay URLs (ws:// or wss://).
+//   - kind 5 (deletion): must have at least one "e" or "a" tag.
+//   - kind 7 (reaction): must have at least one "e" tag.
+//   - kind 30000-39999 (parameterized replaceable events): must have a "d" tag.
+//
+// You can add your own validators by [KindSchemas.Register] or [KindSchemas.RegisterMatching].
+func DefaultKindSchemas() *KindSchemas {
+	return NewKindSchemas().
+		Register(0, validateMetadata).
+		RegisterMatching(func(k int) bool {
+			return k == 3 || (10000 <= k && k < 20000)
+		}, validateListTags).
+		Register(10002, validateRelayList).
+		Register(5, validateDeletion).
+		Register(7, validateReaction).
+		RegisterMatching(KindsAllParamReplaceable, validateParamReplaceable)
+}
+
+// Register registers the validator for events of the given kind.
+func (s *KindSchemas) Register(kind int, validate KindValidator) *KindSchemas {
+	return s.RegisterMatching(func(k int) bool { return k == kind }, validate)
+}
+
+// RegisterMatching registers the validator for events of kinds that match the given matcher function.
+//
+// You can use kind matchers such as [KindsAllReplaceable] here.
+func (s *KindSchemas) RegisterMatching(matchKind func(int) bool, validate KindValidator) *KindSchemas {
+	s.schemas = append(s.schemas, kindSchema{matchKind: matchKind, validate: validate})
+	return s
+}
+
+func (s *KindSchemas) validate(ev *nostr.Event) error {
+	for _, schema := range s.schemas {
+		if !schema.matchKind(ev.Kind) {
+			continue
+		}
+		if err := schema.validate(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConformsToKindSchemas makes an event-sifter that checks if a Nostr event conforms to schemas for its kind, defined by the given [KindSchemas].
+// All validators registered for the kind of the event are applied.
+//
+// This sifter rejects invalid events with message "invalid: <error from the validator>" by default.
+func ConformsToKindSchemas(schemas *KindSchemas) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		if err := schemas.validate(input.Event); err != nil {
+			return rejectWithMsg("invalid: " + err.Error())
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: event doesn't conform to the schema for its kind")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+var metadataStringFields = []string{
+	"name", "display_name", "about", "picture", "banner", "website", "nip05", "lud06", "lud16",
+}
+
+func validateMetadata(ev *nostr.Event) error {
+	var metadata map[string]any
+	if err := json.Unmarshal([]byte(ev.Content), &metadata); err != nil || metadata == nil {
+		return errors.New("content of metadata must be a JSON object")
+	}
+	for _, f := range metadataStringFields {
+		v, ok := metadata[f]
+		if !ok || v == nil {
+			continue
+		}
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("field %q in metadata must be a string", f)
+		}
+	}
+	return nil
+}
+
+func validateListTags(ev *nostr.Event) error {
+	for _, tag := range ev.Tags {
+		switch tag.Key() {
+		case "p":
+			if !nostr.IsValid32ByteHex(tag.Value()) {
+				return fmt.Errorf("value of p tag must be a 64-char lowercase hex pubkey: %q", truncateForMsg(tag.Value()))
+			}
+		case "e":
+			if !nostr.IsValid32ByteHex(tag.Value()) {
+				return fmt.Errorf("value of e tag must be a 64-char lowercase hex event id: %q", truncateForMsg(tag.Value()))
+			}
+		}
+	}
+	return nil
+}
+
+func validateRelayList(ev *nostr.Event) error {
+	for _, tag := range ev.Tags {
+		if tag.Key() == "r" && !isValidRelayURL(tag.Value()) {
+			return fmt.Errorf("value of r tag must be a relay URL: %q", truncateForMsg(tag.Value()))
+		}
+	}
+	return nil
+}
+
+func validateDeletion(ev *nostr.Event) error {
+	if ev.Tags.GetFirst([]string{"e", ""}) == nil && ev.Tags.GetFirst([]string{"a", ""}) == nil {
+		return errors.New("deletion must have at least one e or a tag")
+	}
+	return nil
+}
+
+func validateReaction(ev *nostr.Event) error {
+	if ev.Tags.GetFirst([]string{"e", ""}) == nil {
+		return errors.New("reaction must have an e tag")
+	}
+	return nil
+}
+
+func validateParamReplaceable(ev *nostr.Event) error {
+	for _, tag := range ev.Tags {
+		if tag.Key() == "d" {
+			return nil
+		}
+	}
+	return errors.New("parameterized replaceable event must have a d tag")
+}
+
+func isValidRelayURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "ws" || u.Scheme == "wss") && u.Host != ""
+}
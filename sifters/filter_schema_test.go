@@ -0,0 +1,100 @@
+package sifters
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestConformsToKindSchemas(t *testing.T) {
+	const (
+		hex1 = "003ba9b2c5bd8afeed41a4ce362a8b7fc3ab59c25b6a1359cae9093f296dac01"
+		hex2 = "0000000048ba5812c644dac2f8d53d6ef9b7f143d809a141559e486328ec94af"
+	)
+
+	s := ConformsToKindSchemas(DefaultKindSchemas())
+
+	t.Run("accepts valid events", func(t *testing.T) {
+		evs := []*nostr.Event{
+			{Kind: 0, Content: `{"name":"foo","about":"bar","lud16":null,"custom":123}`},
+			{Kind: 3, Tags: nostr.Tags{{"p", hex1}, {"p", hex2, "wss://relay.example.com", "alice"}}},
+			{Kind: 10000, Tags: nostr.Tags{{"p", hex1}, {"t", "spam"}, {"word", "buy now"}, {"e", hex2}}},
+			{Kind: 10002, Tags: nostr.Tags{{"r", "wss://relay.example.com"}, {"r", "ws://localhost:7777", "read"}}},
+			{Kind: 5, Tags: nostr.Tags{{"e", hex1}}},
+			{Kind: 5, Tags: nostr.Tags{{"a", "30023:" + hex1 + ":article"}}},
+			{Kind: 7, Content: "+", Tags: nostr.Tags{{"e", hex1}, {"p", hex2}}},
+			{Kind: 30023, Tags: nostr.Tags{{"d", "article"}}},
+			{Kind: 30000, Tags: nostr.Tags{{"d"}}},
+			{Kind: 1, Content: "not JSON"},
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result for %v: %+v", ev, res)
+			}
+		}
+	})
+
+	t.Run("rejects invalid events", func(t *testing.T) {
+		tests := []struct {
+			ev      *nostr.Event
+			wantMsg string
+		}{
+			{&nostr.Event{Kind: 0, Content: "hello"}, "invalid: content of metadata must be a JSON object"},
+			{&nostr.Event{Kind: 0, Content: `["name"]`}, "invalid: content of metadata must be a JSON object"},
+			{&nostr.Event{Kind: 0, Content: `{"name":123}`}, `invalid: field "name" in metadata must be a string`},
+			{&nostr.Event{Kind: 3, Tags: nostr.Tags{{"p", hex1}, {"p", "garbage"}}}, `invalid: value of p tag must be a 64-char lowercase hex pubkey: "garbage"`},
+			{&nostr.Event{Kind: 3, Tags: nostr.Tags{{"p", strings.ToUpper(hex1)}}}, `invalid: value of p tag must be a 64-char lowercase hex pubkey: "` + strings.ToUpper(hex1) + `"`},
+			{&nostr.Event{Kind: 10001, Tags: nostr.Tags{{"e", "note1xxx"}}}, `invalid: value of e tag must be a 64-char lowercase hex event id: "note1xxx"`},
+			{&nostr.Event{Kind: 10002, Tags: nostr.Tags{{"r", "https://example.com"}}}, `invalid: value of r tag must be a relay URL: "https://example.com"`},
+			{&nostr.Event{Kind: 3, Tags: nostr.Tags{{"p", strings.Repeat("x", 10000)}}}, `invalid: value of p tag must be a 64-char lowercase hex pubkey: "` + strings.Repeat("x", 64) + `..."`},
+			{&nostr.Event{Kind: 5, Tags: nostr.Tags{{"p", hex1}}}, "invalid: deletion must have at least one e or a tag"},
+			{&nostr.Event{Kind: 7, Content: "+"}, "invalid: reaction must have an e tag"},
+			{&nostr.Event{Kind: 30023, Tags: nostr.Tags{{"delegation", hex1}}}, "invalid: parameterized replaceable event must have a d tag"},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+
+	t.Run("custom validators", func(t *testing.T) {
+		schemas := DefaultKindSchemas().Register(1, func(ev *nostr.Event) error {
+			if ev.Content == "" {
+				return errors.New("text note must not be empty")
+			}
+			return nil
+		})
+		s := ConformsToKindSchemas(schemas)
+
+		res, err := s.Sift(inputWithEvent(&nostr.Event{Kind: 1}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionReject || res.Msg != "invalid: text note must not be empty" {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		// built-in validators are still applied
+		res, err = s.Sift(inputWithEvent(&nostr.Event{Kind: 7}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionReject || res.Msg != "invalid: reaction must have an e tag" {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
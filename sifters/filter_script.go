@@ -0,0 +1,200 @@
+package sifters
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal/langdetect"
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+)
+
+// ContentScripts makes an event-sifter that checks if a content of a Nostr event is written in the given set of writing systems (scripts).
+//
+// Scripts are specified by names of Unicode scripts as in [unicode.Scripts] (e.g. "Latin", "Cyrillic", "Arabic", "Han", "Hiragana", "Katakana" and "Hangul").
+// Note that Japanese texts consist of "Han", "Hiragana" and "Katakana".
+//
+// The input matches if the ratio of letters in the given scripts to all letters in the content is greater than or equal to minRatio.
+// Characters that are not letters (digits, punctuations, emojis etc.) are ignored, and contents without any letters are always accepted.
+//
+// It panics if the list contains unknown script names.
+func ContentScripts(scripts []string, minRatio float64, mode Mode) *SifterUnit {
+	tables := make([]*unicode.RangeTable, 0, len(scripts))
+	for _, s := range scripts {
+		t, ok := unicode.Scripts[s]
+		if !ok {
+			panic(fmt.Sprintf("sifters.ContentScripts: unknown script: %q", s))
+		}
+		tables = append(tables, t)
+	}
+
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		inScripts, letters := 0, 0
+		for _, r := range input.Event.Content {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters++
+			if unicode.In(r, tables...) {
+				inScripts++
+			}
+		}
+		if letters == 0 {
+			return inputAlwaysAccept, nil
+		}
+		return matchResultFromBool(float64(inScripts)/float64(letters) >= minRatio, nil)
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: content must be written in allowed scripts",
+		"blocked: content is written in forbidden scripts",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+// LanguageDetector detects the language of texts.
+//
+// You can plug an external language detection library into [ContentLanguages] by implementing this interface,
+// if languages you want to detect are not supported by [DefaultLanguageDetector].
+type LanguageDetector interface {
+	// DetectLanguage returns the ISO 639-1 code of the language of the text (e.g. "en", "ja"). ok is false if the language can't be determined.
+	DetectLanguage(text string) (lang string, ok bool)
+}
+
+// LanguageDetectorFunc is an adapter to allow the use of functions as a LanguageDetector.
+type LanguageDetectorFunc func(text string) (lang string, ok bool)
+
+func (f LanguageDetectorFunc) DetectLanguage(text string) (string, bool) {
+	return f(text)
+}
+
+// ContentLanguages makes an event-sifter that checks if a content of a Nostr event is written in one of the given languages,
+// detected by the detector. Languages are specified by ISO 639-1 codes (e.g. "en", "ja").
+//
+// If detector is nil, [DefaultLanguageDetector] is used.
+// Contents whose language can't be determined by the detector are always accepted.
+func ContentLanguages(detector LanguageDetector, langs []string, mode Mode) *SifterUnit {
+	if detector == nil {
+		detector = DefaultLanguageDetector()
+	}
+	langSet := utils.SliceToSet(langs)
+
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		lang, ok := detector.DetectLanguage(input.Event.Content)
+		if !ok {
+			return inputAlwaysAccept, nil
+		}
+		_, in := langSet[lang]
+		return matchResultFromBool(in, nil)
+	}
+	defaultRejFn := rejectWithMsgPerMode(
+		mode,
+		"blocked: content must be written in allowed languages",
+		"blocked: content is written in forbidden languages",
+	)
+	return newSifterUnit(matchInput, mode, defaultRejFn)
+}
+
+// languages that can be determined only by scripts
+var scriptLanguages = map[string]string{
+	"Hangul":    "ko",
+	"Thai":      "th",
+	"Greek":     "el",
+	"Hebrew":    "he",
+	"Armenian":  "hy",
+	"Georgian":  "ka",
+	"Khmer":     "km",
+	"Lao":       "lo",
+	"Myanmar":   "my",
+	"Sinhala":   "si",
+	"Tamil":     "ta",
+	"Telugu":    "te",
+	"Kannada":   "kn",
+	"Malayalam": "ml",
+	"Gujarati":  "gu",
+	"Gurmukhi":  "pa",
+}
+
+// ScriptLanguageDetector returns a simple [LanguageDetector] that detects languages only from scripts of texts.
+//
+// It determines only languages that are almost uniquely identified by their scripts,
+// such as Japanese (kana with or without kanji), Chinese (Han with few or no kana), Korean (Hangul), Thai and Greek.
+// Texts mainly written in scripts shared by many languages (e.g. Latin, Cyrillic and Arabic) are considered undeterminable.
+func ScriptLanguageDetector() LanguageDetector {
+	return LanguageDetectorFunc(detectLanguageByScript)
+}
+
+// NgramLanguageDetector returns a [LanguageDetector] that detects languages of texts written in scripts shared by many languages,
+// by comparing character trigrams of texts with the profiles of languages built from sample texts embedded in this library.
+//
+// It determines the following languages:
+//
+//   - Latin script: English (en), Spanish (es), Portuguese (pt), French (fr), German (de), Italian (it), Dutch (nl), Polish (pl), Turkish (tr), Indonesian (id) and Swedish (sv)
+//   - Cyrillic script: Russian (ru), Ukrainian (uk) and Bulgarian (bg)
+//   - Arabic script: Arabic (ar) and Persian (fa)
+//
+// A text is always classified as one of the languages of its main script, even if it is actually written in other languages.
+// URLs and Nostr entities (e.g. "nostr:npub1...") in texts are ignored, and texts too short (less than 10 letters) are considered undeterminable.
+func NgramLanguageDetector() LanguageDetector {
+	return LanguageDetectorFunc(langdetect.Detect)
+}
+
+// DefaultLanguageDetector returns a [LanguageDetector] that combines [ScriptLanguageDetector] and [NgramLanguageDetector].
+// It first tries to detect languages from scripts of texts, and then from character trigrams of them.
+func DefaultLanguageDetector() LanguageDetector {
+	return LanguageDetectorFunc(func(text string) (string, bool) {
+		if lang, ok := detectLanguageByScript(text); ok {
+			return lang, true
+		}
+		return langdetect.Detect(text)
+	})
+}
+
+// min ratio of kana to kana and Han characters in Japanese texts
+const minKanaRatio = 0.1
+
+func detectLanguageByScript(text string) (string, bool) {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		counts[scriptOf(r)]++
+	}
+	if letters == 0 {
+		return "", false
+	}
+
+	// Japanese texts mix Han and kana. Chinese texts may contain a few kana (e.g. loanwords and emoticons), so a minimum ratio of kana is required
+	kana := counts["Hiragana"] + counts["Katakana"]
+	if kana+counts["Han"] > letters/2 && float64(kana) >= minKanaRatio*float64(kana+counts["Han"]) {
+		return "ja", true
+	}
+	if counts["Han"] > letters/2 {
+		return "zh", true
+	}
+	for script, lang := range scriptLanguages {
+		if counts[script] > letters/2 {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// scripts checked by scriptOf, in the order of checking
+var detectedScripts = []string{
+	"Latin", "Han", "Hiragana", "Katakana", "Hangul", "Cyrillic", "Arabic", "Devanagari",
+	"Thai", "Greek", "Hebrew", "Armenian", "Georgian", "Khmer", "Lao", "Myanmar",
+	"Sinhala", "Tamil", "Telugu", "Kannada", "Malayalam", "Gujarati", "Gurmukhi", "Bengali",
+}
+
+func scriptOf(r rune) string {
+	for _, s := range detectedScripts {
+		if unicode.Is(unicode.Scripts[s], r) {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package sifters
+
+import (
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+)
+
+func TestContentScripts(t *testing.T) {
+	t.Run("Allow mode accepts contents mainly written in the scripts", func(t *testing.T) {
+		s := ContentScripts([]string{"Han", "Hiragana", "Katakana"}, 0.5, Allow)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"こんにちは、世界！", strfrui.ActionAccept},
+			{"Nostrのリレーを立てた", strfrui.ActionAccept},
+			{"hello, world!", strfrui.ActionReject},
+			{"I love 日本", strfrui.ActionReject},
+			{"🎉🎉 123", strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+
+	t.Run("Deny mode rejects contents mainly written in the scripts", func(t *testing.T) {
+		s := ContentScripts([]string{"Cyrillic"}, 0.5, Deny)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"Привет, мир!", strfrui.ActionReject},
+			{"hello, мир", strfrui.ActionAccept},
+			{"hello, world!", strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+}
+
+func TestContentScripts_UnknownScript(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("ContentScripts didn't panic with an unknown script")
+		}
+	}()
+	ContentScripts([]string{"Klingon"}, 0.5, Allow)
+}
+
+func TestDetectLanguageByScript(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		wantOk bool
+	}{
+		{"今日はいい天気ですね", "ja", true},
+		{"カタカナ", "ja", true},
+		{"今天天气很好", "zh", true},
+		{"我们今天去卡拉OK唱歌了，还吃了很多好吃的东西ア", "zh", true}, // Chinese with a katakana
+		{"日本語能力試験の結果が発表された", "ja", true},         // kanji-heavy Japanese
+		{"안녕하세요", "ko", true},
+		{"สวัสดีครับ", "th", true},
+		{"Καλημέρα", "el", true},
+		{"hello, world", "", false},
+		{"Привет", "", false},
+		{"123 🎉", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := detectLanguageByScript(tt.text)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("detectLanguageByScript(%q) = (%q, %v), want (%q, %v)", tt.text, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestDefaultLanguageDetector(t *testing.T) {
+	d := DefaultLanguageDetector()
+
+	tests := []struct {
+		text   string
+		want   string
+		wantOk bool
+	}{
+		{"今日はいい天気ですね", "ja", true},
+		{"Καλημέρα σε όλους", "el", true},
+		{"Ich kann nicht glauben, wie schnell dieses Relay ist", "de", true},
+		{"Не мога да повярвам колко бърз е този релей", "bg", true},
+		{"gm", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := d.DetectLanguage(tt.text)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("DetectLanguage(%q) = (%q, %v), want (%q, %v)", tt.text, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestContentLanguages(t *testing.T) {
+	t.Run("uses the default detector if detector is nil", func(t *testing.T) {
+		s := ContentLanguages(nil, []string{"ja", "pt", "uk"}, Allow)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"今日はいい天気ですね", strfrui.ActionAccept},
+			{"안녕하세요", strfrui.ActionReject},
+			{"Não acredito como este relay é rápido", strfrui.ActionAccept},
+			{"No puedo creer lo rápido que es este relé", strfrui.ActionReject},
+			{"Завтра мені треба рано вставати на роботу", strfrui.ActionAccept},
+			{"Завтра мне нужно рано вставать на работу", strfrui.ActionReject},
+			{"I can't believe how fast this relay is", strfrui.ActionReject},
+			{"gm", strfrui.ActionAccept}, // undeterminable
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+
+	t.Run("uses the given detector", func(t *testing.T) {
+		detector := LanguageDetectorFunc(func(text string) (string, bool) {
+			switch text {
+			case "bonjour":
+				return "fr", true
+			case "hola":
+				return "es", true
+			default:
+				return "", false
+			}
+		})
+		s := ContentLanguages(detector, []string{"fr"}, Deny)
+
+		tests := []struct {
+			content string
+			want    strfrui.Action
+		}{
+			{"bonjour", strfrui.ActionReject},
+			{"hola", strfrui.ActionAccept},
+			{"???", strfrui.ActionAccept},
+		}
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithContent(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != tt.want {
+				t.Fatalf("unexpected result for %q: %+v", tt.content, res)
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package sifters
+
+import (
+	"log"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+)
+
+// ValidSignature makes an event-sifter that checks if the ID and the signature of a Nostr event are valid.
+// That is, it recomputes the event ID from the event data as per [NIP-01] and verifies the Schnorr signature on the ID by the author's pubkey.
+//
+// Other sifters trust the event ID as it is (e.g. [PoWMinDifficulty] computes the difficulty from the ID), so you may want to put this sifter in front of them,
+// especially if the relay accepts events that may not have been verified (e.g. events imported via "strfry import").
+//
+// This sifter rejects events with invalid ID or signature with messages prefixed with "invalid:" by default.
+//
+// [NIP-01]: https://github.com/nostr-protocol/nips/blob/master/01.md
+func ValidSignature() *SifterUnit {
+	return validSignature(nil)
+}
+
+// ValidSignatureCached is a variant of [ValidSignature] that caches the results of signature verification in a LRU cache with the given size,
+// in order to avoid verifying the signature of the same event repeatedly.
+//
+// Note that the event ID is always recomputed, so an event with tampered data is never accepted even if its ID is in the cache.
+func ValidSignatureCached(cacheSize int) *SifterUnit {
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		log.Fatalf("sifters.ValidSignatureCached: failed to initialize cache: %v", err)
+	}
+	return validSignature(cache)
+}
+
+type verifiedSigKey struct {
+	id     string
+	pubkey string
+	sig    string
+}
+
+func validSignature(cache *lru.Cache) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		ev := input.Event
+		if ev.GetID() != ev.ID {
+			return rejectWithMsg("invalid: event id doesn't match the event data")
+		}
+
+		cacheKey := verifiedSigKey{id: ev.ID, pubkey: ev.PubKey, sig: ev.Sig}
+		if cache != nil && cache.Contains(cacheKey) {
+			return inputMatch, nil
+		}
+		if ok, err := ev.CheckSignature(); !ok {
+			if err != nil {
+				log.Printf("validSignature: failed to verify signature (id: %s): %v", ev.ID, err)
+			}
+			return rejectWithMsg("invalid: signature is invalid")
+		}
+		if cache != nil {
+			cache.Add(cacheKey, struct{}{})
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: event id or signature is invalid")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
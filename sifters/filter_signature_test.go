@@ -0,0 +1,103 @@
+package sifters
+
+import (
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func signedEvent(t *testing.T, content string) *nostr.Event {
+	t.Helper()
+
+	ev := &nostr.Event{
+		Kind:      1,
+		Content:   content,
+		CreatedAt: nostr.Timestamp(1700000000),
+		Tags:      nostr.Tags{},
+	}
+	if err := ev.Sign(nostr.GeneratePrivateKey()); err != nil {
+		t.Fatalf("failed to sign event: %v", err)
+	}
+	return ev
+}
+
+func TestValidSignature(t *testing.T) {
+	ss := map[string]*SifterUnit{
+		"no cache": ValidSignature(),
+		"cached":   ValidSignatureCached(16),
+	}
+
+	for name, s := range ss {
+		t.Run(name+": accepts if id and signature are valid", func(t *testing.T) {
+			ev := signedEvent(t, "hello")
+
+			// check twice to hit the cache
+			for i := 0; i < 2; i++ {
+				res, err := s.Sift(inputWithEvent(ev))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if res.Action != strfrui.ActionAccept {
+					t.Fatalf("unexpected result: %+v", res)
+				}
+			}
+		})
+
+		t.Run(name+": rejects if id doesn't match the event data", func(t *testing.T) {
+			ev := signedEvent(t, "hello")
+			// verify the original event first, so that it is cached
+			if _, err := s.Sift(inputWithEvent(ev)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tampered := *ev
+			tampered.Content = "tampered"
+
+			res, err := s.Sift(inputWithEvent(&tampered))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != "invalid: event id doesn't match the event data" {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		})
+
+		t.Run(name+": rejects if signature is invalid", func(t *testing.T) {
+			ev := signedEvent(t, "hello")
+			other := signedEvent(t, "hello")
+
+			evs := []*nostr.Event{
+				// signature by another key
+				{ID: ev.ID, PubKey: ev.PubKey, CreatedAt: ev.CreatedAt, Kind: ev.Kind, Tags: ev.Tags, Content: ev.Content, Sig: other.Sig},
+				// malformed signature
+				{ID: ev.ID, PubKey: ev.PubKey, CreatedAt: ev.CreatedAt, Kind: ev.Kind, Tags: ev.Tags, Content: ev.Content, Sig: "malformed"},
+			}
+
+			for _, ev := range evs {
+				res, err := s.Sift(inputWithEvent(ev))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if res.Action != strfrui.ActionReject || res.Msg != "invalid: signature is invalid" {
+					t.Fatalf("unexpected result: %+v", res)
+				}
+			}
+		})
+	}
+
+	t.Run("customized rejection overrides the specific message", func(t *testing.T) {
+		s := ValidSignature().RejectWithMsg("blocked: bad event")
+
+		ev := signedEvent(t, "hello")
+		ev.Content = "tampered"
+
+		res, err := s.Sift(inputWithEvent(ev))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionReject || res.Msg != "blocked: bad event" {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package sifters
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+)
+
+// SizeLimits describes limits on the size and complexity of an event. Zero values mean "unlimited".
+type SizeLimits struct {
+	// Max size of the serialized event (JSON), in bytes.
+	MaxEventBytes int
+
+	// Max length of the content, in bytes.
+	MaxContentBytes int
+
+	// Max length of the content, in runes (Unicode code points).
+	MaxContentRunes int
+
+	// Max number of tags.
+	MaxTags int
+
+	// Max length of each element of tags, in bytes.
+	MaxTagElemBytes int
+
+	// Max total length of all elements of all tags, in bytes.
+	MaxTotalTagBytes int
+}
+
+// SizeLimitsForKinds defines [SizeLimits] for specific kinds of events.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+type SizeLimitsForKinds struct {
+	matchKind func(int) bool
+	limits    SizeLimits
+}
+
+// ForKinds makes the [SizeLimits] l be only applied to events of the given set of kinds.
+func (l SizeLimits) ForKinds(kinds ...int) SizeLimitsForKinds {
+	kindSet := utils.SliceToSet(kinds)
+	return l.ForKindsMatching(func(k int) bool {
+		_, ok := kindSet[k]
+		return ok
+	})
+}
+
+// ForKindsMatching makes the [SizeLimits] l be only applied to events of kinds that match the given matcher function.
+//
+// You can use kind matchers such as [KindsAllReplaceable] here.
+func (l SizeLimits) ForKindsMatching(matchKind func(int) bool) SizeLimitsForKinds {
+	return SizeLimitsForKinds{
+		matchKind: matchKind,
+		limits:    l,
+	}
+}
+
+// EventSize makes an event-sifter that checks if the size and complexity of a Nostr event are within the given limits.
+//
+// This sifter rejects events that exceed the limits with messages prefixed with "invalid:" by default.
+func EventSize(limits SizeLimits) *SifterUnit {
+	return EventSizePerKind(nil, limits)
+}
+
+// EventSizePerKind makes an event-sifter that checks if the size and complexity of a Nostr event are within limits that vary by kind.
+//
+// The limits for each event kind is specified by the given list of [SizeLimitsForKinds]. Each event is checked against the first limits that match its kind.
+// For event kinds for which limits are not defined, defaultLimits is applied.
+//
+// This sifter rejects events that exceed the limits with messages prefixed with "invalid:" by default.
+func EventSizePerKind(limitsPerKind []SizeLimitsForKinds, defaultLimits SizeLimits) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		limits := defaultLimits
+		for _, l := range limitsPerKind {
+			if l.matchKind(input.Event.Kind) {
+				limits = l.limits
+				break
+			}
+		}
+		if msg := checkSizeLimits(input, limits); msg != "" {
+			return rejectWithMsg(msg)
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: event is too large")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+func checkSizeLimits(input *strfrui.Input, limits SizeLimits) string {
+	ev := input.Event
+
+	if limits.MaxContentBytes > 0 && len(ev.Content) > limits.MaxContentBytes {
+		return fmt.Sprintf("invalid: content is too long (max: %d bytes)", limits.MaxContentBytes)
+	}
+	if limits.MaxContentRunes > 0 && utf8.RuneCountInString(ev.Content) > limits.MaxContentRunes {
+		return fmt.Sprintf("invalid: content is too long (max: %d characters)", limits.MaxContentRunes)
+	}
+	if limits.MaxTags > 0 && len(ev.Tags) > limits.MaxTags {
+		return fmt.Sprintf("invalid: too many tags (max: %d)", limits.MaxTags)
+	}
+	if limits.MaxTagElemBytes > 0 || limits.MaxTotalTagBytes > 0 {
+		total := 0
+		for _, tag := range ev.Tags {
+			for _, elem := range tag {
+				if limits.MaxTagElemBytes > 0 && len(elem) > limits.MaxTagElemBytes {
+					return fmt.Sprintf("invalid: tag element is too long (max: %d bytes)", limits.MaxTagElemBytes)
+				}
+				total += len(elem)
+			}
+		}
+		if limits.MaxTotalTagBytes > 0 && total > limits.MaxTotalTagBytes {
+			return fmt.Sprintf("invalid: tags are too large (max: %d bytes in total)", limits.MaxTotalTagBytes)
+		}
+	}
+	// check the whole size at last, since serialization is relatively expensive
+	if limits.MaxEventBytes > 0 && len(ev.String()) > limits.MaxEventBytes {
+		return fmt.Sprintf("invalid: event is too large (max: %d bytes)", limits.MaxEventBytes)
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package sifters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestEventSize(t *testing.T) {
+	s := EventSize(SizeLimits{
+		MaxContentBytes:  12,
+		MaxContentRunes:  5,
+		MaxTags:          3,
+		MaxTagElemBytes:  10,
+		MaxTotalTagBytes: 20,
+	})
+
+	t.Run("accepts events within the limits", func(t *testing.T) {
+		evs := []*nostr.Event{
+			{Content: "hello"},
+			{Tags: nostr.Tags{{"t", "nostr"}, {"t", "zap"}}},
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result for %+v: %+v", ev, res)
+			}
+		}
+	})
+
+	t.Run("rejects events exceeding the limits", func(t *testing.T) {
+		tests := []struct {
+			ev      *nostr.Event
+			wantMsg string
+		}{
+			{&nostr.Event{Content: "hello, world"}, "invalid: content is too long (max: 5 characters)"},
+			// 5 characters but 15 bytes
+			{&nostr.Event{Content: "こんにちは"}, "invalid: content is too long (max: 12 bytes)"},
+			{&nostr.Event{Tags: nostr.Tags{{"t"}, {"t"}, {"t"}, {"t"}}}, "invalid: too many tags (max: 3)"},
+			{&nostr.Event{Tags: nostr.Tags{{"t", "nostrnostrnostr"}}}, "invalid: tag element is too long (max: 10 bytes)"},
+			{&nostr.Event{Tags: nostr.Tags{{"t", "abcdefghi"}, {"t", "abcdefghi"}, {"t", "abcdefghi"}}}, "invalid: tags are too large (max: 20 bytes in total)"},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(tt.ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result for %+v: %+v", tt.ev, res)
+			}
+		}
+	})
+
+	t.Run("checks the size of the serialized event", func(t *testing.T) {
+		ev := &nostr.Event{Content: "hello"}
+		size := len(ev.String())
+
+		res, err := EventSize(SizeLimits{MaxEventBytes: size}).Sift(inputWithEvent(ev))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionAccept {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		res, err = EventSize(SizeLimits{MaxEventBytes: size - 1}).Sift(inputWithEvent(ev))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != strfrui.ActionReject || !strings.HasPrefix(res.Msg, "invalid: event is too large") {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
+
+func TestEventSizePerKind(t *testing.T) {
+	s := EventSizePerKind(
+		[]SizeLimitsForKinds{
+			SizeLimits{MaxContentBytes: 100}.ForKinds(30023),
+			SizeLimits{MaxContentBytes: 0}.ForKindsMatching(KindsAllEphemeral),
+		},
+		SizeLimits{MaxContentBytes: 10},
+	)
+
+	tests := []struct {
+		kind    int
+		content string
+		want    strfrui.Action
+	}{
+		{1, "short", strfrui.ActionAccept},
+		{1, strings.Repeat("a", 11), strfrui.ActionReject},
+		{30023, strings.Repeat("a", 100), strfrui.ActionAccept},
+		{30023, strings.Repeat("a", 101), strfrui.ActionReject},
+		{20000, strings.Repeat("a", 1000), strfrui.ActionAccept},
+	}
+
+	for _, tt := range tests {
+		res, err := s.Sift(inputWithEvent(&nostr.Event{Kind: tt.kind, Content: tt.content}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != tt.want {
+			t.Fatalf("unexpected result for kind %d (len: %d): %+v", tt.kind, len(tt.content), res)
+		}
+	}
+}
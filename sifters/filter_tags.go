@@ -0,0 +1,87 @@
+package sifters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// TagLimits describes limits on the number of tags in an event. Zero values mean "unlimited".
+type TagLimits struct {
+	// Max number of tags per event.
+	MaxTags int
+
+	// Max number of tags per tag name (e.g. {"p": 100, "t": 10}).
+	MaxTagsPerName map[string]int
+}
+
+// ValidTags makes an event-sifter that checks if tags of a Nostr event are well-formed and within the given limits.
+//
+// It checks the following rules on tags:
+//
+//   - values of "e" and "p" tags must be 64-char lowercase hex (event ID / pubkey).
+//   - values of "a" tags must be valid coordinates in the form of "<kind>:<pubkey>:<d tag>".
+//   - relay hints of "e", "p" and "a" tags (the 3rd element) must be empty or valid relay URLs (ws:// or wss://).
+//   - the number of tags must be within the given limits.
+//
+// This sifter rejects events with messages prefixed with "invalid:" by default.
+func ValidTags(limits TagLimits) *SifterUnit {
+	matchInput := func(input *strfrui.Input) (inputMatchResult, error) {
+		tags := input.Event.Tags
+		if limits.MaxTags > 0 && len(tags) > limits.MaxTags {
+			return rejectWithMsg(fmt.Sprintf("invalid: too many tags (max: %d)", limits.MaxTags))
+		}
+
+		counts := make(map[string]int)
+		for _, tag := range tags {
+			name := tag.Key()
+			counts[name]++
+			if limit, ok := limits.MaxTagsPerName[name]; ok && limit > 0 && counts[name] > limit {
+				return rejectWithMsg(fmt.Sprintf("invalid: too many %q tags (max: %d)", name, limit))
+			}
+			if err := validateTag(tag); err != nil {
+				return rejectWithMsg("invalid: " + err.Error())
+			}
+		}
+		return inputMatch, nil
+	}
+	defaultRejFn := internal.RejectWithMsg("invalid: event has malformed tags")
+	return newSifterUnit(matchInput, Allow, defaultRejFn)
+}
+
+func validateTag(tag nostr.Tag) error {
+	name := tag.Key()
+	switch name {
+	case "e", "p":
+		if !nostr.IsValid32ByteHex(tag.Value()) {
+			return fmt.Errorf("value of %s tag must be a 64-char lowercase hex: %q", name, truncateForMsg(tag.Value()))
+		}
+	case "a":
+		if !isValidCoordinate(tag.Value()) {
+			return fmt.Errorf("value of a tag must be a coordinate (<kind>:<pubkey>:<d tag>): %q", truncateForMsg(tag.Value()))
+		}
+	default:
+		return nil
+	}
+
+	if len(tag) >= 3 && tag[2] != "" && !isValidRelayURL(tag[2]) {
+		return fmt.Errorf("relay hint of %s tag must be a relay URL: %q", name, truncateForMsg(tag[2]))
+	}
+	return nil
+}
+
+// isValidCoordinate checks if s is a valid coordinate of an addressable event: "<kind>:<pubkey>:<d tag>".
+func isValidCoordinate(s string) bool {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return false
+	}
+	if k, err := strconv.Atoi(parts[0]); err != nil || k < 0 {
+		return false
+	}
+	return nostr.IsValid32ByteHex(parts[1])
+}
@@ -0,0 +1,68 @@
+package sifters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestValidTags(t *testing.T) {
+	const (
+		hex1 = "003ba9b2c5bd8afeed41a4ce362a8b7fc3ab59c25b6a1359cae9093f296dac01"
+		hex2 = "0000000048ba5812c644dac2f8d53d6ef9b7f143d809a141559e486328ec94af"
+	)
+
+	s := ValidTags(TagLimits{
+		MaxTags:        5,
+		MaxTagsPerName: map[string]int{"t": 2},
+	})
+
+	t.Run("accepts events with well-formed tags", func(t *testing.T) {
+		evs := []*nostr.Event{
+			{Tags: nostr.Tags{}},
+			{Tags: nostr.Tags{{"e", hex1}, {"p", hex2}}},
+			{Tags: nostr.Tags{{"e", hex1, "wss://relay.example.com", "root"}, {"p", hex2, ""}}},
+			{Tags: nostr.Tags{{"a", "30023:" + hex1 + ":my-article", "ws://localhost:7777"}}},
+			{Tags: nostr.Tags{{"a", "10000:" + hex1 + ":"}}},
+			{Tags: nostr.Tags{{"t", "nostr"}, {"t", "zap"}, {"x", "whatever"}}},
+		}
+
+		for _, ev := range evs {
+			res, err := s.Sift(inputWithEvent(ev))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionAccept {
+				t.Fatalf("unexpected result for %v: %+v", ev.Tags, res)
+			}
+		}
+	})
+
+	t.Run("rejects events with malformed tags or too many tags", func(t *testing.T) {
+		tests := []struct {
+			tags    nostr.Tags
+			wantMsg string
+		}{
+			{nostr.Tags{{"e", "abc"}}, `invalid: value of e tag must be a 64-char lowercase hex: "abc"`},
+			{nostr.Tags{{"p"}}, `invalid: value of p tag must be a 64-char lowercase hex: ""`},
+			{nostr.Tags{{"e", hex1, "https://relay.example.com"}}, `invalid: relay hint of e tag must be a relay URL: "https://relay.example.com"`},
+			{nostr.Tags{{"a", "30023:" + hex1}}, `invalid: value of a tag must be a coordinate (<kind>:<pubkey>:<d tag>): "30023:` + hex1[:58] + `..."`},
+			{nostr.Tags{{"a", "article:" + hex1 + ":d"}}, `invalid: value of a tag must be a coordinate (<kind>:<pubkey>:<d tag>): "article:` + hex1[:56] + `..."`},
+			{nostr.Tags{{"p", hex1, "https://" + strings.Repeat("x", 10000)}}, `invalid: relay hint of p tag must be a relay URL: "https://` + strings.Repeat("x", 56) + `..."`},
+			{nostr.Tags{{"t", "a"}, {"t", "b"}, {"t", "c"}}, `invalid: too many "t" tags (max: 2)`},
+			{nostr.Tags{{"x"}, {"x"}, {"x"}, {"x"}, {"x"}, {"x"}}, "invalid: too many tags (max: 5)"},
+		}
+
+		for _, tt := range tests {
+			res, err := s.Sift(inputWithEvent(&nostr.Event{Tags: tt.tags}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Action != strfrui.ActionReject || res.Msg != tt.wantMsg {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		}
+	})
+}
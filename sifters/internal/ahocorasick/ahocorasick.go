@@ -0,0 +1,187 @@
+// Package ahocorasick implements the Aho-Corasick algorithm, which finds occurrences of many patterns in a text at once.
+//
+// The automaton works on bytes, so it works with UTF-8 texts as long as patterns are also UTF-8.
+package ahocorasick
+
+import "sort"
+
+// Matcher is an Aho-Corasick automaton built from a set of patterns. It is immutable, so it is safe for concurrent use.
+//
+// Transitions of all nodes are stored in a single slice sorted by node and byte, rather than a map per node,
+// so that the automaton of a large set of patterns stays compact and contains no pointers for GC to scan.
+type Matcher struct {
+	nodes    []node
+	edges    []edge
+	root     [256]int32 // transitions from the root node, for fast lookups. 0 if there is no transition
+	patterns []string
+}
+
+type node struct {
+	// transitions from this node are edges[first:first+n]
+	first int32
+	n     int32
+	fail  int32
+	// index of the pattern that ends at this node, or -1
+	out int32
+	// index of the nearest node reachable by following failure links that has an output, or -1
+	dictLink int32
+}
+
+type edge struct {
+	b  byte
+	to int32
+}
+
+// New builds a Matcher from patterns. Empty patterns are ignored.
+func New(patterns []string) *Matcher {
+	// build the trie. children[i] is the list of edges from node i, sorted by byte
+	children := [][]edge{nil}
+	outs := []int32{-1}
+	for i, p := range patterns {
+		if p == "" {
+			continue
+		}
+		cur := int32(0)
+		for j := 0; j < len(p); j++ {
+			es := children[cur]
+			k := sort.Search(len(es), func(k int) bool { return es[k].b >= p[j] })
+			if k < len(es) && es[k].b == p[j] {
+				cur = es[k].to
+				continue
+			}
+			nxt := int32(len(children))
+			children = append(children, nil)
+			outs = append(outs, -1)
+			es = append(es, edge{})
+			copy(es[k+1:], es[k:])
+			es[k] = edge{b: p[j], to: nxt}
+			children[cur] = es
+			cur = nxt
+		}
+		if outs[cur] < 0 {
+			outs[cur] = int32(i)
+		}
+	}
+
+	// flatten edges into a single slice
+	m := &Matcher{
+		nodes:    make([]node, len(children)),
+		patterns: patterns,
+	}
+	numEdges := 0
+	for _, es := range children {
+		numEdges += len(es)
+	}
+	m.edges = make([]edge, 0, numEdges)
+	for i, es := range children {
+		m.nodes[i] = node{
+			first:    int32(len(m.edges)),
+			n:        int32(len(es)),
+			out:      outs[i],
+			dictLink: -1,
+		}
+		m.edges = append(m.edges, es...)
+	}
+	for _, e := range m.edges[:m.nodes[0].n] {
+		m.root[e.b] = e.to
+	}
+
+	// compute failure links and dictionary links in BFS order
+	queue := make([]int32, 0, len(m.nodes))
+	for _, e := range m.children(0) {
+		queue = append(queue, e.to)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, e := range m.children(cur) {
+			child := e.to
+			f := m.nodes[cur].fail
+			for {
+				if nxt, ok := m.next(f, e.b); ok {
+					m.nodes[child].fail = nxt
+					break
+				}
+				if f == 0 {
+					m.nodes[child].fail = 0
+					break
+				}
+				f = m.nodes[f].fail
+			}
+
+			cf := m.nodes[child].fail
+			if m.nodes[cf].out >= 0 {
+				m.nodes[child].dictLink = cf
+			} else {
+				m.nodes[child].dictLink = m.nodes[cf].dictLink
+			}
+			queue = append(queue, child)
+		}
+	}
+	return m
+}
+
+func (m *Matcher) children(n int32) []edge {
+	nd := m.nodes[n]
+	return m.edges[nd.first : nd.first+nd.n]
+}
+
+// next returns the node reached from the node n by the byte b.
+func (m *Matcher) next(n int32, b byte) (int32, bool) {
+	if n == 0 {
+		nxt := m.root[b]
+		return nxt, nxt != 0
+	}
+	es := m.children(n)
+	// binary search over edges sorted by byte
+	lo, hi := 0, len(es)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if es[mid].b < b {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(es) && es[lo].b == b {
+		return es[lo].to, true
+	}
+	return 0, false
+}
+
+// Match describes an occurrence of a pattern in a text.
+type Match struct {
+	// Index of the pattern in the list passed to New.
+	Pattern int
+	// Start and End are byte offsets of the occurrence in the text (text[Start:End] == pattern).
+	Start, End int
+}
+
+// Iterate calls f for each occurrence of patterns in text, in order of their end positions.
+// It stops iteration when f returns false.
+func (m *Matcher) Iterate(text string, f func(Match) bool) {
+	cur := int32(0)
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		for {
+			if nxt, ok := m.next(cur, b); ok {
+				cur = nxt
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+
+		for n := cur; n > 0; n = m.nodes[n].dictLink {
+			if p := m.nodes[n].out; p >= 0 {
+				end := i + 1
+				if !f(Match{Pattern: int(p), Start: end - len(m.patterns[p]), End: end}) {
+					return
+				}
+			}
+		}
+	}
+}
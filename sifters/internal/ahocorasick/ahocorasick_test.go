@@ -0,0 +1,223 @@
+package ahocorasick
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func collectMatches(m *Matcher, text string) []Match {
+	matches := make([]Match, 0)
+	m.Iterate(text, func(mt Match) bool {
+		matches = append(matches, mt)
+		return true
+	})
+	return matches
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		text     string
+		want     []Match
+	}{
+		{
+			name:     "overlapping patterns",
+			patterns: []string{"he", "she", "hers"},
+			text:     "ushers",
+			want: []Match{
+				{Pattern: 1, Start: 1, End: 4},
+				{Pattern: 0, Start: 2, End: 4},
+				{Pattern: 2, Start: 2, End: 6},
+			},
+		},
+		{
+			name:     "pattern is a suffix of another pattern",
+			patterns: []string{"abcd", "bc", "c"},
+			text:     "xabcdx",
+			want: []Match{
+				{Pattern: 1, Start: 2, End: 4},
+				{Pattern: 2, Start: 3, End: 4},
+				{Pattern: 0, Start: 1, End: 5},
+			},
+		},
+		{
+			name:     "repeated occurrences",
+			patterns: []string{"aa"},
+			text:     "aaaa",
+			want: []Match{
+				{Pattern: 0, Start: 0, End: 2},
+				{Pattern: 0, Start: 1, End: 3},
+				{Pattern: 0, Start: 2, End: 4},
+			},
+		},
+		{
+			name:     "duplicate patterns report the first index",
+			patterns: []string{"spam", "ham", "spam"},
+			text:     "spam",
+			want: []Match{
+				{Pattern: 0, Start: 0, End: 4},
+			},
+		},
+		{
+			name:     "empty patterns never match",
+			patterns: []string{"", "b", ""},
+			text:     "abc",
+			want: []Match{
+				{Pattern: 1, Start: 1, End: 2},
+			},
+		},
+		{
+			name:     "only empty patterns",
+			patterns: []string{""},
+			text:     "abc",
+			want:     []Match{},
+		},
+		{
+			name:     "multibyte UTF-8 with byte offsets",
+			patterns: []string{"スパム", "ム", "nostr"},
+			text:     "これはスパムnostr",
+			want: []Match{
+				{Pattern: 0, Start: 9, End: 18},
+				{Pattern: 1, Start: 15, End: 18},
+				{Pattern: 2, Start: 18, End: 23},
+			},
+		},
+		{
+			name:     "no match",
+			patterns: []string{"foo", "bar"},
+			text:     "baz qux",
+			want:     []Match{},
+		},
+		{
+			name:     "empty text",
+			patterns: []string{"foo"},
+			text:     "",
+			want:     []Match{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.patterns)
+			got := collectMatches(m, tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("matches = %+v, want %+v", got, tt.want)
+			}
+			for _, mt := range got {
+				if tt.text[mt.Start:mt.End] != tt.patterns[mt.Pattern] {
+					t.Fatalf("text[%d:%d] = %q, want %q", mt.Start, mt.End, tt.text[mt.Start:mt.End], tt.patterns[mt.Pattern])
+				}
+			}
+		})
+	}
+}
+
+func TestMatcher_StopIteration(t *testing.T) {
+	m := New([]string{"a"})
+
+	n := 0
+	m.Iterate("aaaa", func(Match) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Fatalf("f called %d times, want 2", n)
+	}
+}
+
+// compares the matcher with brute-force matching on random inputs
+func TestMatcher_BruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "é", "語"}
+	randomString := func(maxLen int) string {
+		var sb strings.Builder
+		for i := rng.Intn(maxLen + 1); i > 0; i-- {
+			sb.WriteString(alphabet[rng.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+
+	for i := 0; i < 2000; i++ {
+		patterns := make([]string, rng.Intn(6)+1)
+		for j := range patterns {
+			patterns[j] = randomString(4)
+		}
+		text := randomString(20)
+
+		got := collectMatches(New(patterns), text)
+		want := bruteForceMatches(patterns, text)
+		sortMatches(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("patterns: %q, text: %q\nmatches = %+v, want %+v", patterns, text, got, want)
+		}
+	}
+}
+
+func bruteForceMatches(patterns []string, text string) []Match {
+	firstIndex := make(map[string]int)
+	for i, p := range patterns {
+		if _, ok := firstIndex[p]; !ok && p != "" {
+			firstIndex[p] = i
+		}
+	}
+
+	matches := make([]Match, 0)
+	for p, i := range firstIndex {
+		for start := 0; start+len(p) <= len(text); start++ {
+			if text[start:start+len(p)] == p {
+				matches = append(matches, Match{Pattern: i, Start: start, End: start + len(p)})
+			}
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].End != matches[j].End {
+			return matches[i].End < matches[j].End
+		}
+		return matches[i].Start < matches[j].Start
+	})
+}
+
+// keyword list of the size typical for large blocklists
+func benchmarkPatterns() []string {
+	rng := rand.New(rand.NewSource(1))
+	patterns := make([]string, 20000)
+	for i := range patterns {
+		b := make([]byte, 4+rng.Intn(9))
+		for j := range b {
+			b[j] = byte('a' + rng.Intn(26))
+		}
+		patterns[i] = string(b)
+	}
+	return patterns
+}
+
+func BenchmarkNew(b *testing.B) {
+	patterns := benchmarkPatterns()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New(patterns)
+	}
+}
+
+func BenchmarkIterate(b *testing.B) {
+	m := New(benchmarkPatterns())
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog, gm nostr! ", 20)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Iterate(text, func(Match) bool { return true })
+	}
+}
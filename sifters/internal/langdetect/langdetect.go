@@ -0,0 +1,205 @@
+// Package langdetect detects languages of texts written in scripts shared by many languages (Latin, Cyrillic and Arabic).
+//
+// It classifies texts by naive Bayes over character trigrams of words.
+// Trigram profiles of languages are built from the sample texts embedded in the package (corpus/<ISO 639-1 code>.txt).
+package langdetect
+
+import (
+	"embed"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// scripts in which languages are detected
+var scripts = []string{"Latin", "Cyrillic", "Arabic"}
+
+const (
+	// texts with fewer letters than this are too short to determine the language
+	minLetters = 10
+
+	// additive smoothing parameter for trigrams that don't appear in sample texts
+	smoothing = 0.5
+)
+
+type profile struct {
+	lang    string
+	logProb map[string]float64
+	unseen  float64 // log probability of trigrams that don't appear in the sample text
+}
+
+// language profiles per script
+var profiles = buildProfiles()
+
+func buildProfiles() map[string][]*profile {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		panic(err)
+	}
+
+	type sample struct {
+		lang   string
+		counts map[string]int
+		total  int
+	}
+	samples := make(map[string][]sample)
+	for _, e := range entries {
+		b, err := corpus.ReadFile(path.Join("corpus", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		text := string(b)
+		script, ok := dominantScript(text)
+		if !ok {
+			panic("langdetect: sample text is not written in supported scripts: " + e.Name())
+		}
+
+		s := sample{lang: strings.TrimSuffix(e.Name(), ".txt"), counts: make(map[string]int)}
+		for _, g := range trigrams(text, script) {
+			s.counts[g]++
+			s.total++
+		}
+		samples[script] = append(samples[script], s)
+	}
+
+	res := make(map[string][]*profile)
+	for script, ss := range samples {
+		vocab := make(map[string]struct{})
+		for _, s := range ss {
+			for g := range s.counts {
+				vocab[g] = struct{}{}
+			}
+		}
+
+		for _, s := range ss {
+			denom := float64(s.total) + smoothing*float64(len(vocab))
+			p := &profile{
+				lang:    s.lang,
+				logProb: make(map[string]float64, len(s.counts)),
+				unseen:  math.Log(smoothing / denom),
+			}
+			for g, c := range s.counts {
+				p.logProb[g] = math.Log((float64(c) + smoothing) / denom)
+			}
+			res[script] = append(res[script], p)
+		}
+	}
+	return res
+}
+
+// Languages returns ISO 639-1 codes of languages that can be detected, in alphabetical order.
+func Languages() []string {
+	langs := make([]string, 0)
+	for _, ps := range profiles {
+		for _, p := range ps {
+			langs = append(langs, p.lang)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// URLs and Nostr entities are not written in any languages
+var nonLinguisticRegexp = regexp.MustCompile(`(?i)(?:https?://|wss?://|nostr:)\S+|\b(?:npub|nsec|nprofile|note|nevent|naddr|nrelay)1[02-9ac-hj-np-z]+`)
+
+// Detect returns the ISO 639-1 code of the language of the text.
+// ok is false if the text is not mainly written in Latin, Cyrillic or Arabic script, or it is too short to determine the language.
+func Detect(text string) (lang string, ok bool) {
+	text = nonLinguisticRegexp.ReplaceAllString(text, " ")
+
+	script, ok := dominantScript(text)
+	if !ok {
+		return "", false
+	}
+	ps := profiles[script]
+	if len(ps) == 0 {
+		return "", false
+	}
+
+	grams := trigrams(text, script)
+	var (
+		best      *profile
+		bestScore = math.Inf(-1)
+	)
+	for _, p := range ps {
+		score := 0.0
+		for _, g := range grams {
+			if lp, ok := p.logProb[g]; ok {
+				score += lp
+			} else {
+				score += p.unseen
+			}
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best.lang, true
+}
+
+// dominantScript returns the supported script in which the majority of letters in the text are written.
+func dominantScript(text string) (string, bool) {
+	counts := make([]int, len(scripts))
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if i := scriptIndex(r); i >= 0 {
+			counts[i]++
+		}
+	}
+
+	for i, c := range counts {
+		if c >= minLetters && c > letters/2 {
+			return scripts[i], true
+		}
+	}
+	return "", false
+}
+
+func scriptIndex(r rune) int {
+	for i, s := range scripts {
+		if unicode.Is(unicode.Scripts[s], r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// trigrams returns character trigrams of lowercased words in the text, padded with spaces (e.g. "hey" → " he", "hey", "ey ").
+// Words are runs of letters in the script. Other characters separate words.
+func trigrams(text string, script string) []string {
+	table := unicode.Scripts[script]
+	grams := make([]string, 0, len(text))
+	word := []rune{' '}
+
+	flush := func() {
+		if len(word) > 1 {
+			word = append(word, ' ')
+			for i := 0; i+3 <= len(word); i++ {
+				grams = append(grams, string(word[i:i+3]))
+			}
+		}
+		word = word[:1]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) && unicode.Is(table, r):
+			word = append(word, unicode.ToLower(r))
+		case unicode.Is(unicode.Mn, r) || r == '\'' || r == '’':
+			// ignore diacritics written as combining marks (e.g. Arabic harakat) and apostrophes within words
+		default:
+			flush()
+		}
+	}
+	flush()
+	return grams
+}
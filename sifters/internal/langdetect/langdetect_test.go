@@ -0,0 +1,57 @@
+package langdetect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		wantOk bool
+	}{
+		{"I can't believe how fast this relay is", "en", true},
+		{"No puedo creer lo rápido que es este relé", "es", true},
+		{"Não acredito como este relay é rápido", "pt", true},
+		{"mañana tengo que trabajar temprano", "es", true},
+		{"amanhã tenho que trabalhar cedo", "pt", true},
+		{"Je n'arrive pas à croire à quel point ce relais est rapide", "fr", true},
+		{"Ich kann nicht glauben, wie schnell dieses Relay ist", "de", true},
+		{"Non riesco a credere quanto sia veloce questo relay", "it", true},
+		{"Ik kan niet geloven hoe snel deze relay is", "nl", true},
+		{"Nie mogę uwierzyć, jak szybki jest ten przekaźnik", "pl", true},
+		{"Bu rölenin ne kadar hızlı olduğuna inanamıyorum", "tr", true},
+		{"Aku tidak percaya betapa cepatnya relay ini", "id", true},
+		{"Jag kan inte fatta hur snabbt det här reläet är", "sv", true},
+		{"Не могу поверить, насколько быстрый этот релей", "ru", true},
+		{"Не можу повірити, наскільки швидкий цей релей", "uk", true},
+		{"Не мога да повярвам колко бърз е този релей", "bg", true},
+		{"Завтра мне нужно рано вставать на работу", "ru", true},
+		{"Завтра мені треба рано вставати на роботу", "uk", true},
+		{"لا أصدق كم هذا المرحل سريع", "ar", true},
+		{"باورم نمی‌شود این رله چقدر سریع است", "fa", true},
+
+		// URLs and Nostr entities are ignored
+		{"Não acredito https://example.com/how-fast-this-relay-is nostr:npub1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq", "pt", true},
+
+		// undeterminable
+		{"gm", "", false},
+		{"https://example.com/some/long/path/to/a/page", "", false},
+		{"今日はいい天気ですね", "", false},
+		{"123 🎉", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Detect(tt.text)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("Detect(%q) = (%q, %v), want (%q, %v)", tt.text, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	want := []string{"ar", "bg", "de", "en", "es", "fa", "fr", "id", "it", "nl", "pl", "pt", "ru", "sv", "tr", "uk"}
+	if got := Languages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Languages() = %v, want %v", got, want)
+	}
+}
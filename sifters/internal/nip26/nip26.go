@@ -0,0 +1,117 @@
+// Package nip26 implements validation of delegation tags defined in NIP-26.
+package nip26
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ErrNoDelegation is returned from Delegator if the event doesn't have a delegation tag.
+var ErrNoDelegation = errors.New("event has no delegation tag")
+
+// Delegator validates the delegation tag of the event, and returns the pubkey of the delegator if it is valid.
+//
+// Delegation is valid if the delegation token is a valid signature by the delegator
+// and the event satisfies all conditions in the delegation string.
+// Multiple kind conditions are interpreted as "kind is one of them".
+func Delegator(ev *nostr.Event) (string, error) {
+	tag := ev.Tags.GetFirst([]string{"delegation", ""})
+	if tag == nil {
+		return "", ErrNoDelegation
+	}
+	if len(*tag) < 4 {
+		return "", errors.New("malformed delegation tag")
+	}
+	delegator, conds, token := (*tag)[1], (*tag)[2], (*tag)[3]
+
+	if err := verifyToken(delegator, ev.PubKey, conds, token); err != nil {
+		return "", err
+	}
+	if err := checkConditions(ev, conds); err != nil {
+		return "", err
+	}
+	return delegator, nil
+}
+
+func verifyToken(delegator, delegatee, conds, token string) error {
+	pkBytes, err := hex.DecodeString(delegator)
+	if err != nil {
+		return fmt.Errorf("delegator pubkey is invalid hex: %w", err)
+	}
+	pk, err := schnorr.ParsePubKey(pkBytes)
+	if err != nil {
+		return fmt.Errorf("delegator pubkey is invalid: %w", err)
+	}
+	sigBytes, err := hex.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("delegation token is invalid hex: %w", err)
+	}
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return fmt.Errorf("delegation token is invalid: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(DelegationString(delegatee, conds)))
+	if !sig.Verify(hash[:], pk) {
+		return errors.New("delegation token is not signed by the delegator")
+	}
+	return nil
+}
+
+// DelegationString returns the string to be signed by the delegator.
+func DelegationString(delegatee, conds string) string {
+	return fmt.Sprintf("nostr:delegation:%s:%s", delegatee, conds)
+}
+
+func checkConditions(ev *nostr.Event, conds string) error {
+	var (
+		kindSpecified bool
+		kindMatched   bool
+	)
+	for _, cond := range strings.Split(conds, "&") {
+		switch {
+		case cond == "":
+			continue
+
+		case strings.HasPrefix(cond, "kind="):
+			k, err := strconv.Atoi(strings.TrimPrefix(cond, "kind="))
+			if err != nil {
+				return fmt.Errorf("malformed delegation condition: %q", cond)
+			}
+			kindSpecified = true
+			kindMatched = kindMatched || ev.Kind == k
+
+		case strings.HasPrefix(cond, "created_at<"):
+			t, err := strconv.ParseInt(strings.TrimPrefix(cond, "created_at<"), 10, 64)
+			if err != nil {
+				return fmt.Errorf("malformed delegation condition: %q", cond)
+			}
+			if int64(ev.CreatedAt) >= t {
+				return errors.New("event is created after the delegation period")
+			}
+
+		case strings.HasPrefix(cond, "created_at>"):
+			t, err := strconv.ParseInt(strings.TrimPrefix(cond, "created_at>"), 10, 64)
+			if err != nil {
+				return fmt.Errorf("malformed delegation condition: %q", cond)
+			}
+			if int64(ev.CreatedAt) <= t {
+				return errors.New("event is created before the delegation period")
+			}
+
+		default:
+			return fmt.Errorf("unknown delegation condition: %q", cond)
+		}
+	}
+	if kindSpecified && !kindMatched {
+		return errors.New("event kind is not allowed by the delegation")
+	}
+	return nil
+}
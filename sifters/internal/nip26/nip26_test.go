@@ -0,0 +1,202 @@
+package nip26
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+type testKey struct {
+	sk string
+	pk string
+}
+
+func newTestKey(t *testing.T) testKey {
+	t.Helper()
+
+	sk := nostr.GeneratePrivateKey()
+	pk, err := nostr.GetPublicKey(sk)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+	return testKey{sk: sk, pk: pk}
+}
+
+// signToken signs the delegation string for the delegatee and the conditions by the delegator.
+func signToken(t *testing.T, delegator testKey, delegatee string, conds string) string {
+	t.Helper()
+
+	skBytes, _ := hex.DecodeString(delegator.sk)
+	sk, _ := btcec.PrivKeyFromBytes(skBytes)
+	hash := sha256.Sum256([]byte(DelegationString(delegatee, conds)))
+	sig, err := schnorr.Sign(sk, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign delegation token: %v", err)
+	}
+	return hex.EncodeToString(sig.Serialize())
+}
+
+func TestDelegator(t *testing.T) {
+	delegator, delegatee, other := newTestKey(t), newTestKey(t), newTestKey(t)
+
+	// event authored by delegatee, delegated with a token signed by signer for conditions
+	event := func(signer testKey, conds string, kind int, createdAt nostr.Timestamp) *nostr.Event {
+		return &nostr.Event{
+			PubKey:    delegatee.pk,
+			Kind:      kind,
+			CreatedAt: createdAt,
+			Tags:      nostr.Tags{{"delegation", delegator.pk, conds, signToken(t, signer, delegatee.pk, conds)}},
+		}
+	}
+	const conds = "kind=1&kind=7&created_at>1000&created_at<2000"
+
+	tests := []struct {
+		name    string
+		ev      *nostr.Event
+		wantErr string // empty if the delegation is valid
+	}{
+		{
+			name: "valid delegation",
+			ev:   event(delegator, conds, 1, 1500),
+		},
+		{
+			name: "valid delegation with another allowed kind",
+			ev:   event(delegator, conds, 7, 1500),
+		},
+		{
+			name: "no conditions",
+			ev:   event(delegator, "", 30023, 1),
+		},
+		{
+			name: "empty condition segments are ignored",
+			ev:   event(delegator, "&kind=1&&", 1, 1),
+		},
+		{
+			name:    "malformed tag",
+			ev:      &nostr.Event{PubKey: delegatee.pk, Tags: nostr.Tags{{"delegation", delegator.pk, conds}}},
+			wantErr: "malformed delegation tag",
+		},
+		{
+			name: "delegator pubkey is invalid hex",
+			ev: &nostr.Event{
+				PubKey: delegatee.pk,
+				Tags:   nostr.Tags{{"delegation", "zz", conds, signToken(t, delegator, delegatee.pk, conds)}},
+			},
+			wantErr: "delegator pubkey is invalid hex",
+		},
+		{
+			name: "token is invalid hex",
+			ev: &nostr.Event{
+				PubKey: delegatee.pk,
+				Tags:   nostr.Tags{{"delegation", delegator.pk, conds, "not-a-signature"}},
+			},
+			wantErr: "delegation token is invalid hex",
+		},
+		{
+			name: "token is not a signature",
+			ev: &nostr.Event{
+				PubKey: delegatee.pk,
+				Tags:   nostr.Tags{{"delegation", delegator.pk, conds, "abcd"}},
+			},
+			wantErr: "delegation token is invalid",
+		},
+		{
+			name:    "token signed by other key",
+			ev:      event(other, conds, 1, 1500),
+			wantErr: "delegation token is not signed by the delegator",
+		},
+		{
+			name: "token signed for other conditions",
+			ev: &nostr.Event{
+				PubKey:    delegatee.pk,
+				Kind:      1,
+				CreatedAt: 1500,
+				Tags:      nostr.Tags{{"delegation", delegator.pk, "kind=1", signToken(t, delegator, delegatee.pk, conds)}},
+			},
+			wantErr: "delegation token is not signed by the delegator",
+		},
+		{
+			name: "token signed for other delegatee",
+			ev: &nostr.Event{
+				PubKey:    delegatee.pk,
+				Kind:      1,
+				CreatedAt: 1500,
+				Tags:      nostr.Tags{{"delegation", delegator.pk, conds, signToken(t, delegator, other.pk, conds)}},
+			},
+			wantErr: "delegation token is not signed by the delegator",
+		},
+		{
+			name:    "kind not allowed",
+			ev:      event(delegator, conds, 0, 1500),
+			wantErr: "event kind is not allowed by the delegation",
+		},
+		{
+			name:    "malformed kind condition",
+			ev:      event(delegator, "kind=abc", 1, 1500),
+			wantErr: `malformed delegation condition: "kind=abc"`,
+		},
+		{
+			name:    "empty kind condition",
+			ev:      event(delegator, "kind=", 1, 1500),
+			wantErr: `malformed delegation condition: "kind="`,
+		},
+		{
+			name:    "malformed created_at condition",
+			ev:      event(delegator, "created_at<tomorrow", 1, 1500),
+			wantErr: `malformed delegation condition: "created_at<tomorrow"`,
+		},
+		{
+			name:    "unknown condition",
+			ev:      event(delegator, "kind=1&created_at=1500", 1, 1500),
+			wantErr: `unknown delegation condition: "created_at=1500"`,
+		},
+		{
+			name: "created_at just after the lower bound",
+			ev:   event(delegator, conds, 1, 1001),
+		},
+		{
+			name:    "created_at at the lower bound",
+			ev:      event(delegator, conds, 1, 1000),
+			wantErr: "event is created before the delegation period",
+		},
+		{
+			name: "created_at just before the upper bound",
+			ev:   event(delegator, conds, 1, 1999),
+		},
+		{
+			name:    "created_at at the upper bound",
+			ev:      event(delegator, conds, 1, 2000),
+			wantErr: "event is created after the delegation period",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Delegator(tt.ev)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != delegator.pk {
+					t.Fatalf("unexpected delegator: %s", got)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("unexpected error: %v (want: %q)", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDelegator_NoDelegation(t *testing.T) {
+	_, err := Delegator(&nostr.Event{Tags: nostr.Tags{{"p", "abc"}}})
+	if !errors.Is(err, ErrNoDelegation) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
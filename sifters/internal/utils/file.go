@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// WriteFileAtomic writes data to the file at path.
+// It writes to a temporary file in the same directory then renames it, so that the file is never left half-written.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SnapshotFile persists snapshots of a state to a file, so that the state can be encoded and written without holding the lock that guards it.
+//
+// Call Next while holding the lock, to number the snapshot taken under it. Then call Write with the number after releasing the lock.
+// Write skips snapshots older than the one already written, so that a slow writer never overwrites a newer state with an older one.
+type SnapshotFile struct {
+	Path string
+
+	gen     atomic.Uint64
+	mu      sync.Mutex
+	written uint64
+}
+
+// Next returns the number of the next snapshot.
+func (f *SnapshotFile) Next() uint64 {
+	return f.gen.Add(1)
+}
+
+// Write writes the data encoded by encode to the file, unless a newer snapshot has already been written.
+func (f *SnapshotFile) Write(gen uint64, encode func() ([]byte, error)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if gen <= f.written {
+		return nil
+	}
+	b, err := encode()
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(f.Path, b); err != nil {
+		return err
+	}
+	f.written = gen
+	return nil
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	if err := WriteFileAtomic(path, []byte("old")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "new" {
+		t.Fatalf("unexpected content: %q", b)
+	}
+
+	// temporary files must be cleaned up
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("unexpected files in the directory: %v", files)
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	f := &SnapshotFile{Path: path}
+
+	encode := func(s string) func() ([]byte, error) {
+		return func() ([]byte, error) { return []byte(s), nil }
+	}
+
+	older := f.Next()
+	newer := f.Next()
+
+	if err := f.Write(newer, encode("newer")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the older snapshot must not overwrite the newer one
+	if err := f.Write(older, encode("older")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "newer" {
+		t.Fatalf("unexpected content: %q", b)
+	}
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal/nip26"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// LimitKey derives a key that identifies the target of rate limiting (e.g. a user) from an input.
+// Inputs that share the same key consume the same quota.
+//
+// If shouldLimit is false, rate limit is not imposed on the input.
+//
+// You can use LimitKeys with [ByKey] and [ByKeyAndKind] to rate-limit by arbitrary features of inputs.
+// Built-in LimitKeys are available via [UserKey.LimitKey], [KeyByTag], [KeyBySourceRelay] and [KeyGlobal],
+// and they can be combined into one by [CompositeKey].
+// Of course, you can also write your own LimitKey as a function.
+type LimitKey func(input *strfrui.Input) (shouldLimit bool, key string)
+
+// LimitKey returns a [LimitKey] that identifies users in the way specified by the UserKey.
+//
+// Note that the returned LimitKey doesn't impose a rate limit to events not from end-users (i.e. events imported from other relays).
+func (uk UserKey) LimitKey() LimitKey {
+	return func(input *strfrui.Input) (bool, string) {
+		if !input.SourceType.IsEndUser() {
+			return false, ""
+		}
+		switch uk {
+		case IPAddr:
+			if isValidIPAddr(input.SourceInfo) {
+				return true, input.SourceInfo
+			}
+			return false, ""
+		case PubKey:
+			return true, input.Event.PubKey
+		case PubKeyWithDelegation:
+			if delegator, err := nip26.Delegator(input.Event); err == nil {
+				return true, delegator
+			}
+			return true, input.Event.PubKey
+		default:
+			return false, ""
+		}
+	}
+}
+
+// KeyByTag returns a [LimitKey] that identifies inputs by the value of the first tag with the given name in an event.
+// For example, KeyByTag("e") makes replies to the same event share a quota,
+// and KeyByTag("p") makes mentions to the same user share a quota.
+//
+// Events that don't have any tag with the name are not rate-limited.
+// Note that the returned LimitKey doesn't impose a rate limit to events not from end-users (i.e. events imported from other relays).
+func KeyByTag(tagName string) LimitKey {
+	return func(input *strfrui.Input) (bool, string) {
+		if !input.SourceType.IsEndUser() {
+			return false, ""
+		}
+		tag := input.Event.Tags.GetFirst([]string{tagName, ""})
+		if tag == nil {
+			return false, ""
+		}
+		return true, tagName + ":" + tag.Value()
+	}
+}
+
+// KeyBySourceRelay returns a [LimitKey] that identifies inputs by the URL of the source relay.
+//
+// Contrary to other built-in LimitKeys, the returned LimitKey imposes a rate limit only to events imported from other relays
+// (i.e. events whose source type is [strfrui.SourceTypeStream] or [strfrui.SourceTypeSync]).
+func KeyBySourceRelay() LimitKey {
+	return func(input *strfrui.Input) (bool, string) {
+		switch input.SourceType {
+		case strfrui.SourceTypeStream, strfrui.SourceTypeSync:
+			if input.SourceInfo == "" {
+				return false, ""
+			}
+			if u := nostr.NormalizeURL(input.SourceInfo); u != "" {
+				return true, u
+			}
+			return true, input.SourceInfo
+		default:
+			return false, ""
+		}
+	}
+}
+
+// KeyGlobal returns a [LimitKey] that assigns the same key to all inputs regardless of their sources,
+// so that all inputs share a single relay-wide quota.
+func KeyGlobal() LimitKey {
+	return func(_ *strfrui.Input) (bool, string) {
+		return true, "global"
+	}
+}
+
+// CompositeKey combines multiple [LimitKey]s into one.
+// The resulting LimitKey joins keys derived by all the given LimitKeys (e.g. pubkey + source IP address).
+//
+// If any of the given LimitKeys says "shouldn't limit", the input is not rate-limited.
+func CompositeKey(keys ...LimitKey) LimitKey {
+	return func(input *strfrui.Input) (bool, string) {
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			shouldLimit, key := k(input)
+			if !shouldLimit {
+				return false, ""
+			}
+			parts = append(parts, key)
+		}
+		return len(parts) > 0, strings.Join(parts, "/")
+	}
+}
+
+func isValidIPAddr(s string) bool {
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/jiftechnify/strfrui"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestKeyByTag(t *testing.T) {
+	t.Parallel()
+
+	k := KeyByTag("e")
+
+	tests := []struct {
+		name        string
+		input       *strfrui.Input
+		shouldLimit bool
+		key         string
+	}{
+		{
+			name:        "first tag value",
+			input:       inputWithEvent(&nostr.Event{Tags: nostr.Tags{{"p", "pk"}, {"e", "root"}, {"e", "reply"}}}),
+			shouldLimit: true,
+			key:         "e:root",
+		},
+		{
+			name:        "no tag",
+			input:       inputWithEvent(&nostr.Event{Tags: nostr.Tags{{"p", "pk"}}}),
+			shouldLimit: false,
+		},
+		{
+			name: "not from end-user",
+			input: &strfrui.Input{
+				SourceType: strfrui.SourceTypeStream,
+				SourceInfo: "wss://relay.example.com",
+				Event:      &nostr.Event{Tags: nostr.Tags{{"e", "root"}}},
+			},
+			shouldLimit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		shouldLimit, key := k(tt.input)
+		if shouldLimit != tt.shouldLimit || key != tt.key {
+			t.Errorf("%s: want (%v, %q), got (%v, %q)", tt.name, tt.shouldLimit, tt.key, shouldLimit, key)
+		}
+	}
+}
+
+func TestKeyBySourceRelay(t *testing.T) {
+	t.Parallel()
+
+	k := KeyBySourceRelay()
+
+	tests := []struct {
+		name        string
+		input       *strfrui.Input
+		shouldLimit bool
+		key         string
+	}{
+		{
+			name:        "stream",
+			input:       &strfrui.Input{SourceType: strfrui.SourceTypeStream, SourceInfo: "wss://Relay.example.com/"},
+			shouldLimit: true,
+			key:         "wss://relay.example.com",
+		},
+		{
+			name:        "sync",
+			input:       &strfrui.Input{SourceType: strfrui.SourceTypeSync, SourceInfo: "wss://relay.example.com"},
+			shouldLimit: true,
+			key:         "wss://relay.example.com",
+		},
+		{
+			name:        "end-user",
+			input:       &strfrui.Input{SourceType: strfrui.SourceTypeIP4, SourceInfo: "192.168.1.1"},
+			shouldLimit: false,
+		},
+		{
+			name:        "import",
+			input:       &strfrui.Input{SourceType: strfrui.SourceTypeImport},
+			shouldLimit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		shouldLimit, key := k(tt.input)
+		if shouldLimit != tt.shouldLimit || key != tt.key {
+			t.Errorf("%s: want (%v, %q), got (%v, %q)", tt.name, tt.shouldLimit, tt.key, shouldLimit, key)
+		}
+	}
+}
+
+func TestCompositeKey(t *testing.T) {
+	t.Parallel()
+
+	k := CompositeKey(PubKey.LimitKey(), IPAddr.LimitKey())
+
+	shouldLimit, key := k(&strfrui.Input{
+		SourceType: strfrui.SourceTypeIP4,
+		SourceInfo: "192.168.1.1",
+		Event:      &nostr.Event{PubKey: "pubkey"},
+	})
+	if !shouldLimit || key != "pubkey/192.168.1.1" {
+		t.Errorf("want (true, %q), got (%v, %q)", "pubkey/192.168.1.1", shouldLimit, key)
+	}
+
+	// if one of keys says "shouldn't limit", the composite key also says so
+	shouldLimit, _ = k(&strfrui.Input{
+		SourceType: strfrui.SourceTypeIP4,
+		SourceInfo: "???",
+		Event:      &nostr.Event{PubKey: "pubkey"},
+	})
+	if shouldLimit {
+		t.Errorf("unexpectedly should limit")
+	}
+}
+
+func TestByKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("key: PubKey + IPAddr", func(t *testing.T) {
+		t.Parallel()
+
+		s := ByKey(QuotaPerMin(1), CompositeKey(PubKey.LimitKey(), IPAddr.LimitKey()))
+
+		input := func(pubkey, addr string) *strfrui.Input {
+			return &strfrui.Input{
+				SourceType: strfrui.SourceTypeIP4,
+				SourceInfo: addr,
+				Event:      &nostr.Event{PubKey: pubkey},
+			}
+		}
+
+		expectResult(t, strfrui.ActionAccept)(s.Sift(input("1", "192.168.1.1")))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(input("1", "192.168.1.2")))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(input("2", "192.168.1.1")))
+
+		expectResult(t, strfrui.ActionReject)(s.Sift(input("1", "192.168.1.1")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(input("1", "192.168.1.2")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(input("2", "192.168.1.1")))
+	})
+
+	t.Run("key: referenced event", func(t *testing.T) {
+		t.Parallel()
+
+		s := ByKey(QuotaPerMin(1).WithBurst(1), KeyByTag("e"))
+
+		replyTo := func(id string) *strfrui.Input {
+			return inputWithEvent(&nostr.Event{Kind: 1, Tags: nostr.Tags{{"e", id}}})
+		}
+
+		expectResult(t, strfrui.ActionAccept)(s.Sift(replyTo("thread1")))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(replyTo("thread1")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(replyTo("thread1")))
+
+		// other thread has its own quota
+		expectResult(t, strfrui.ActionAccept)(s.Sift(replyTo("thread2")))
+
+		// events without e tag are not limited
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputWithEvent(&nostr.Event{Kind: 1})))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputWithEvent(&nostr.Event{Kind: 1})))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputWithEvent(&nostr.Event{Kind: 1})))
+	})
+}
+
+func TestPubKeyWithDelegation(t *testing.T) {
+	t.Parallel()
+
+	delegatorSK := nostr.GeneratePrivateKey()
+	delegatorPK, _ := nostr.GetPublicKey(delegatorSK)
+	delegateePK, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	skBytes, _ := hex.DecodeString(delegatorSK)
+	sk, _ := btcec.PrivKeyFromBytes(skBytes)
+	hash := sha256.Sum256([]byte("nostr:delegation:" + delegateePK + ":kind=1"))
+	sig, err := schnorr.Sign(sk, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign delegation token: %v", err)
+	}
+	delegationTag := nostr.Tag{"delegation", delegatorPK, "kind=1", hex.EncodeToString(sig.Serialize())}
+
+	k := PubKeyWithDelegation.LimitKey()
+
+	tests := []struct {
+		name string
+		ev   *nostr.Event
+		key  string
+	}{
+		{"validly delegated", &nostr.Event{PubKey: delegateePK, Kind: 1, Tags: nostr.Tags{delegationTag}}, delegatorPK},
+		{"conditions unmet", &nostr.Event{PubKey: delegateePK, Kind: 7, Tags: nostr.Tags{delegationTag}}, delegateePK},
+		{"not delegated", &nostr.Event{PubKey: delegateePK, Kind: 1}, delegateePK},
+	}
+
+	for _, tt := range tests {
+		shouldLimit, key := k(inputWithEvent(tt.ev))
+		if !shouldLimit || key != tt.key {
+			t.Errorf("%s: want (true, %q), got (%v, %q)", tt.name, tt.key, shouldLimit, key)
+		}
+	}
+}
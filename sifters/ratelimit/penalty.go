@@ -0,0 +1,278 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+	"github.com/jiftechnify/strfrui/sifters/internal"
+	"github.com/jiftechnify/strfrui/sifters/internal/utils"
+)
+
+// Penalty imposes escalating temporary bans on users who repeatedly exceed rate limits.
+//
+// Each time a user exceeds the quota of a rate-limiting sifter, Penalty counts it as a "violation".
+// Once the number of violations reaches the threshold, the user is banned for a while, and all events from the user are rejected during the ban.
+// The duration of bans escalates each time the user gets banned again (e.g. 5 min → 1 h → 24 h).
+// Records of users who have behaved well for a while are forgotten (see [Penalty.ResetAfter]).
+//
+// Use [SifterUnit.WithPenalty] to attach a Penalty to rate-limiting sifters.
+// A Penalty can be shared among multiple sifters as long as they derive keys in the same way.
+//
+// Penalty rejects events from banned users with message: "rate-limited: you are temporarily banned due to repeated rate limit violations" by default.
+// If you want to customize rejection behavior, call [Penalty.RejectWithMsg], [Penalty.RejectWithMsgFromInput] or [Penalty.ShadowReject] methods on it.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+// Instead, use [NewPenalty] function to construct an instance of Penalty.
+type Penalty struct {
+	mu sync.Mutex
+
+	threshold    int
+	banDurations []time.Duration
+	resetAfter   time.Duration
+	state        *utils.SnapshotFile
+	reject       internal.RejectionFn
+
+	records   map[string]*penaltyRecord
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+type penaltyRecord struct {
+	// number of violations since the last ban
+	Violations int `json:"violations"`
+	// number of bans so far. It determines the duration of the next ban
+	Level         int       `json:"level"`
+	LastViolation time.Time `json:"lastViolation"`
+	BannedUntil   time.Time `json:"bannedUntil"`
+}
+
+// Ban describes a ban imposed on a user by [Penalty].
+type Ban struct {
+	// The key of the banned user.
+	Key string `json:"key"`
+	// The number of bans imposed on the user so far, including the current one.
+	Level int `json:"level"`
+	// When the ban expires.
+	Until time.Time `json:"until"`
+}
+
+// NewPenalty creates a [Penalty] that bans a user when the user exceeds the quota threshold times.
+//
+// banDurations specify durations of successive bans: the first ban lasts for banDurations[0], the second for banDurations[1], and so on.
+// Bans after the last one last for the last duration.
+//
+// By default, records of users are forgotten if they have not exceeded the quota for 7 days.
+func NewPenalty(threshold int, banDurations ...time.Duration) *Penalty {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Penalty{
+		threshold:    threshold,
+		banDurations: banDurations,
+		resetAfter:   7 * 24 * time.Hour,
+		reject:       internal.RejectWithMsg("rate-limited: you are temporarily banned due to repeated rate limit violations"),
+		records:      make(map[string]*penaltyRecord),
+		now:          time.Now,
+	}
+}
+
+// ResetAfter sets the duration after which a record of an user is forgotten if the user haven't exceeded the quota since the last violation.
+// Forgotten users are treated as first offenders in their next violation.
+func (p *Penalty) ResetAfter(d time.Duration) *Penalty {
+	p.resetAfter = d
+	return p
+}
+
+// PersistTo makes the penalty state persisted to the file at the given path.
+//
+// If the file already exists, the state is restored from it. After that, the state is saved to the file every time the set of bans changes.
+// The file is a JSON, so that admins can inspect who is banned and until when.
+func (p *Penalty) PersistTo(path string) (*Penalty, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read penalty state file: %w", err)
+	}
+	if err == nil {
+		records := make(map[string]*penaltyRecord)
+		if err := json.Unmarshal(b, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse penalty state file: %w", err)
+		}
+		// drop records broken by manual edits of the file (e.g. null or negative counts)
+		for k, r := range records {
+			if r == nil || r.Violations < 0 || r.Level < 0 {
+				log.Printf("ratelimit: dropped invalid penalty record for %q in the state file", k)
+				delete(records, k)
+			}
+		}
+		p.records = records
+	}
+	p.state = &utils.SnapshotFile{Path: path}
+	return p, nil
+}
+
+// ShadowReject sets the penalty's rejection behavior to "shadow-reject",
+// which pretend to accept events from banned users but actually reject them.
+func (p *Penalty) ShadowReject() *Penalty {
+	p.reject = internal.ShadowReject
+	return p
+}
+
+// RejectWithMsg makes the penalty reject events from banned users with the given message.
+func (p *Penalty) RejectWithMsg(msg string) *Penalty {
+	p.reject = internal.RejectWithMsg(msg)
+	return p
+}
+
+// RejectWithMsgFromInput makes the penalty reject events from banned users with the message derived from the input by the given function.
+func (p *Penalty) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string) *Penalty {
+	p.reject = internal.RejectWithMsgFromInput(getMsg)
+	return p
+}
+
+// Bans returns the list of bans currently in effect, sorted by expiration time.
+func (p *Penalty) Bans() []Ban {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	bans := make([]Ban, 0)
+	for key, r := range p.records {
+		if now.Before(r.BannedUntil) {
+			bans = append(bans, Ban{Key: key, Level: r.Level, Until: r.BannedUntil})
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Until.Before(bans[j].Until)
+	})
+	return bans
+}
+
+// Unban lifts the ban on the user identified by the key, and forgets all past violations of the user.
+func (p *Penalty) Unban(key string) {
+	p.mu.Lock()
+	if _, ok := p.records[key]; !ok {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.records, key)
+	gen, records := p.snapshot()
+	p.mu.Unlock()
+
+	p.save(gen, records)
+}
+
+func (p *Penalty) isBanned(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.records[key]
+	if !ok {
+		return false
+	}
+	return p.now().Before(r.BannedUntil)
+}
+
+func (p *Penalty) recordViolation(key string) {
+	var (
+		gen     uint64
+		records map[string]penaltyRecord
+	)
+	p.mu.Lock()
+	if p.recordViolationLocked(key) {
+		gen, records = p.snapshot()
+	}
+	p.mu.Unlock()
+
+	// write the state outside the lock, so that saving it doesn't block other inputs
+	p.save(gen, records)
+}
+
+// recordViolationLocked records a violation by the user, and reports whether the user got banned.
+// Caller must hold p.mu.
+func (p *Penalty) recordViolationLocked(key string) bool {
+	now := p.now()
+	p.prune(now)
+
+	r, ok := p.records[key]
+	if !ok || p.shouldForget(r, now) {
+		r = &penaltyRecord{}
+		p.records[key] = r
+	}
+	r.Violations++
+	r.LastViolation = now
+
+	if r.Violations < p.threshold || len(p.banDurations) == 0 {
+		return false
+	}
+
+	// ban the user
+	d := p.banDurations[min(r.Level, len(p.banDurations)-1)]
+	r.Level++
+	r.Violations = 0
+	r.BannedUntil = now.Add(d)
+	log.Printf("ratelimit: banned %q for %v due to repeated rate limit violations (level: %d)", key, d, r.Level)
+	return true
+}
+
+func (p *Penalty) shouldForget(r *penaltyRecord, now time.Time) bool {
+	if now.Before(r.BannedUntil) {
+		return false
+	}
+	// count the period of good behavior from the end of the last ban, if any
+	since := r.LastViolation
+	if r.BannedUntil.After(since) {
+		since = r.BannedUntil
+	}
+	return p.resetAfter > 0 && now.Sub(since) >= p.resetAfter
+}
+
+// prune forgets records of users who have behaved well for a while.
+// To keep it cheap, it runs at most once per minute.
+func (p *Penalty) prune(now time.Time) {
+	if now.Sub(p.lastPrune) < time.Minute {
+		return
+	}
+	p.lastPrune = now
+
+	for key, r := range p.records {
+		if p.shouldForget(r, now) {
+			delete(p.records, key)
+		}
+	}
+}
+
+// snapshot copies the records to be saved to the state file, if any.
+// It returns nil records if the state isn't persisted. Caller must hold p.mu.
+func (p *Penalty) snapshot() (uint64, map[string]penaltyRecord) {
+	if p.state == nil {
+		return 0, nil
+	}
+	records := make(map[string]penaltyRecord, len(p.records))
+	for k, r := range p.records {
+		records[k] = *r
+	}
+	return p.state.Next(), records
+}
+
+// save writes the snapshot of the records to the state file.
+func (p *Penalty) save(gen uint64, records map[string]penaltyRecord) {
+	if records == nil {
+		return
+	}
+	err := p.state.Write(gen, func() ([]byte, error) {
+		return json.MarshalIndent(records, "", "  ")
+	})
+	if err != nil {
+		log.Printf("ratelimit: failed to save penalty state: %v", err)
+	}
+}
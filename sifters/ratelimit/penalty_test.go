@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jiftechnify/strfrui"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func TestPenalty(t *testing.T) {
+	t.Parallel()
+
+	t.Run("escalates bans on repeated violations", func(t *testing.T) {
+		t.Parallel()
+
+		clk := &fakeClock{t: time.Unix(1000, 0)}
+		p := NewPenalty(2, 5*time.Minute, 1*time.Hour)
+		p.now = clk.now
+
+		s := ByUser(QuotaPerHour(1), PubKey).WithPenalty(p)
+
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("1")))
+		// 1st violation
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		if len(p.Bans()) != 0 {
+			t.Fatalf("unexpected bans: %v", p.Bans())
+		}
+		// 2nd violation: banned for 5 min
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		bans := p.Bans()
+		if len(bans) != 1 || bans[0].Key != "1" || bans[0].Level != 1 || !bans[0].Until.Equal(clk.t.Add(5*time.Minute)) {
+			t.Fatalf("unexpected bans: %v", bans)
+		}
+
+		// other users are not affected
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("2")))
+
+		// ban expires
+		clk.advance(5 * time.Minute)
+		if len(p.Bans()) != 0 {
+			t.Fatalf("unexpected bans: %v", p.Bans())
+		}
+
+		// more violations: banned for 1 hour
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		bans = p.Bans()
+		if len(bans) != 1 || bans[0].Level != 2 || !bans[0].Until.Equal(clk.t.Add(1*time.Hour)) {
+			t.Fatalf("unexpected bans: %v", bans)
+		}
+
+		// bans after the last one lasts for the last duration
+		clk.advance(1 * time.Hour)
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		bans = p.Bans()
+		if len(bans) != 1 || bans[0].Level != 3 || !bans[0].Until.Equal(clk.t.Add(1*time.Hour)) {
+			t.Fatalf("unexpected bans: %v", bans)
+		}
+	})
+
+	t.Run("rejects all events from banned users", func(t *testing.T) {
+		t.Parallel()
+
+		clk := &fakeClock{t: time.Unix(1000, 0)}
+		p := NewPenalty(1, 5*time.Minute).ShadowReject()
+		p.now = clk.now
+
+		s := ByUserAndKind([]QuotaForKinds{QuotaPerHour(1).ForKinds(1)}, PubKey).WithPenalty(p)
+
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+
+		// banned: events of other kinds are also rejected
+		expectResult(t, strfrui.ActionShadowReject)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+
+		// lifted ban manually
+		p.Unban("1")
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+	})
+
+	t.Run("forgets well-behaved users", func(t *testing.T) {
+		t.Parallel()
+
+		clk := &fakeClock{t: time.Unix(1000, 0)}
+		p := NewPenalty(2, 5*time.Minute).ResetAfter(1 * time.Hour)
+		p.now = clk.now
+
+		s := ByUser(QuotaPerHour(1), PubKey).WithPenalty(p)
+
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("1")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+
+		clk.advance(1 * time.Hour)
+
+		// violation count is reset, so this is the "first" violation
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+		if len(p.Bans()) != 0 {
+			t.Fatalf("unexpected bans: %v", p.Bans())
+		}
+	})
+
+	t.Run("persists state", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "penalty.json")
+
+		clk := &fakeClock{t: time.Now()}
+		p, err := NewPenalty(1, 5*time.Minute).PersistTo(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		p.now = clk.now
+
+		s := ByUser(QuotaPerHour(1), PubKey).WithPenalty(p)
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("1")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("1")))
+
+		// restore from the state file
+		p2, err := NewPenalty(1, 5*time.Minute).PersistTo(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		p2.now = clk.now
+
+		bans := p2.Bans()
+		if len(bans) != 1 || bans[0].Key != "1" {
+			t.Fatalf("unexpected bans: %v", bans)
+		}
+		s2 := ByUser(QuotaPerHour(1), PubKey).WithPenalty(p2)
+		expectResult(t, strfrui.ActionReject)(s2.Sift(inputFromPubkey("1")))
+	})
+
+	t.Run("drops invalid records in the state file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "penalty.json")
+		state := `{
+			"null": null,
+			"negative": {"violations": -1, "level": 0},
+			"banned": {"violations": 0, "level": 1, "bannedUntil": "2999-01-01T00:00:00Z"}
+		}`
+		if err := os.WriteFile(path, []byte(state), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		p, err := NewPenalty(1, 5*time.Minute).PersistTo(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		bans := p.Bans()
+		if len(bans) != 1 || bans[0].Key != "banned" {
+			t.Fatalf("unexpected bans: %v", bans)
+		}
+
+		s := ByUser(QuotaPerHour(10), PubKey).WithPenalty(p)
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("null")))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("negative")))
+		expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("banned")))
+	})
+}
@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jiftechnify/strfrui/sifters/internal/utils"
@@ -17,8 +18,10 @@ type Quota throttled.RateQuota
 //
 // This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
 type QuotaForKinds struct {
-	matchKind func(int) bool
-	quota     Quota
+	matchKind   func(int) bool
+	quota       Quota
+	name        string
+	acrossKinds bool
 }
 
 // QuotaPerSec creates a [Quota] with max rate of n per second.
@@ -52,11 +55,15 @@ func (q Quota) ForKinds(kinds ...int) QuotaForKinds {
 			return ok
 		},
 		quota: q,
+		name:  fmt.Sprintf("kinds %v", kinds),
 	}
 }
 
 // ForKindsMatching makes the [Quota] q be only applied to events of kinds that match the given matcher function.
 //
+// Like quotas defined by [Quota.ForKinds], the quota is imposed on each kind separately.
+// Use [QuotaForKinds.AcrossKinds] to impose it on all the matching kinds in total.
+//
 // You can use kind matchers defined in [github.com/jiftechnify/strfrui/sifters], such as [github.com/jiftechnify/strfrui/sifters.KindsAllReplaceable].
 func (q Quota) ForKindsMatching(matchKind func(int) bool) QuotaForKinds {
 	return QuotaForKinds{
@@ -64,3 +71,66 @@ func (q Quota) ForKindsMatching(matchKind func(int) bool) QuotaForKinds {
 		quota:     q,
 	}
 }
+
+// ForAllKinds makes the [Quota] q be applied to events of all kinds in total.
+// It works as a catch-all quota, which is imposed in addition to quotas for specific kinds.
+//
+// The quota is named "all kinds" by default.
+func (q Quota) ForAllKinds() QuotaForKinds {
+	return QuotaForKinds{
+		matchKind:   func(int) bool { return true },
+		quota:       q,
+		name:        "all kinds",
+		acrossKinds: true,
+	}
+}
+
+// AcrossKinds makes the quota be imposed on all the matching kinds in total, instead of each kind separately.
+//
+// For example, QuotaPerMin(10).ForKinds(1, 6, 7).AcrossKinds() allows 10 events/min of kind 1, 6 and 7 in total,
+// whereas QuotaPerMin(10).ForKinds(1, 6, 7) allows 10 events/min for each of them.
+func (q QuotaForKinds) AcrossKinds() QuotaForKinds {
+	q.acrossKinds = true
+	return q
+}
+
+// Named names the quota. The name is used in rejection messages to tell which quota was exceeded.
+//
+// Quotas defined by [Quota.ForKinds] are named after the kinds (e.g. "kinds [1 7]"), and ones defined by [Quota.ForAllKinds] are named "all kinds" by default.
+// Unnamed quotas are referred by the position in the list of quotas (e.g. "#1").
+func (q QuotaForKinds) Named(name string) QuotaForKinds {
+	q.name = name
+	return q
+}
+
+// QuotaTier defines a quota of write requests for a specific group of users.
+//
+// This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
+type QuotaTier struct {
+	matchUser func(string) bool
+	quota     Quota
+}
+
+// ForUsers makes the [Quota] q be only applied to the given set of users.
+// Users are pubkeys or IP addresses, depending on the [UserKey] passed to [ByUserTiered].
+func (q Quota) ForUsers(users ...string) QuotaTier {
+	userSet := utils.SliceToSet(users)
+	return QuotaTier{
+		matchUser: func(user string) bool {
+			_, ok := userSet[user]
+			return ok
+		},
+		quota: q,
+	}
+}
+
+// ForUsersMatching makes the [Quota] q be only applied to users that match the given matcher function.
+// Users are pubkeys or IP addresses, depending on the [UserKey] passed to [ByUserTiered].
+//
+// You can pass a function that always returns true to define the quota for users who don't belong to any other tiers.
+func (q Quota) ForUsersMatching(matchUser func(string) bool) QuotaTier {
+	return QuotaTier{
+		matchUser: matchUser,
+		quota:     q,
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net/netip"
+	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/jiftechnify/strfrui"
 	"github.com/jiftechnify/strfrui/sifters/internal"
 	"github.com/throttled/throttled/v2"
@@ -23,10 +24,22 @@ const (
 
 	// Use the pubkey of an event as an user identifier.
 	PubKey
+
+	// Use the pubkey of the delegator as an user identifier if the event is validly delegated by NIP-26.
+	// Otherwise, use the pubkey of the event, just like PubKey.
+	PubKeyWithDelegation
 )
 
-type selectRateLimiterFn func(*strfrui.Input) throttled.RateLimiterCtx
-type rateLimitKeyDeriveFn func(*strfrui.Input) (shouldLimit bool, key string)
+// appliedRateLimiter is a rate-limiter to be applied to an input, with the key to be passed to it.
+type appliedRateLimiter struct {
+	rateLimiter throttled.RateLimiterCtx
+	key         string
+	quotaName   string // name of the quota, used in rejection messages. empty if the sifter has only one quota
+}
+
+// selectRateLimitersFn selects rate-limiters to be applied to the input.
+// limitKey is the key derived by the LimitKey of the sifter.
+type selectRateLimitersFn func(input *strfrui.Input, limitKey string) []appliedRateLimiter
 
 // SifterUnit is base structure of rate-limiting event-sifter logic.
 //
@@ -36,10 +49,11 @@ type rateLimitKeyDeriveFn func(*strfrui.Input) (shouldLimit bool, key string)
 //
 // This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
 type SifterUnit struct {
-	selectLimiter  selectRateLimiterFn
-	deriveLimitKey rateLimitKeyDeriveFn
+	selectLimiters selectRateLimitersFn
+	deriveLimitKey LimitKey
 	exclude        func(*strfrui.Input) bool
-	reject         internal.RejectionFn
+	reject         internal.RejectionFn // if nil, reject with the default message naming the exceeded quota
+	penalty        *Penalty
 }
 
 func (s *SifterUnit) Sift(input *strfrui.Input) (*strfrui.Result, error) {
@@ -50,24 +64,57 @@ func (s *SifterUnit) Sift(input *strfrui.Input) (*strfrui.Result, error) {
 	if !shouldLimit {
 		return input.Accept()
 	}
-	rateLimiter := s.selectLimiter(input)
-	if rateLimiter == nil {
+	if s.penalty != nil && s.penalty.isBanned(limitKey) {
+		return s.penalty.reject(input), nil
+	}
+	rateLimiters := s.selectLimiters(input, limitKey)
+	if len(rateLimiters) == 0 {
 		return input.Accept()
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	limited, _, err := rateLimiter.RateLimitCtx(ctx, limitKey, 1)
-	if err != nil {
-		return nil, err
+	// check (and charge) all the quotas, then reject if any of them is exceeded
+	var exceeded *appliedRateLimiter
+	for i, l := range rateLimiters {
+		limited, _, err := l.rateLimiter.RateLimitCtx(ctx, l.key, 1)
+		if err != nil {
+			return nil, err
+		}
+		if limited && exceeded == nil {
+			exceeded = &rateLimiters[i]
+		}
 	}
-	if limited {
-		return s.reject(input), nil
+	if exceeded != nil {
+		if s.penalty != nil {
+			s.penalty.recordViolation(limitKey)
+		}
+		return s.rejectExceeded(input, exceeded.quotaName), nil
 	}
 	return input.Accept()
 }
 
+func (s *SifterUnit) rejectExceeded(input *strfrui.Input, quotaName string) *strfrui.Result {
+	if s.reject != nil {
+		return s.reject(input)
+	}
+	if quotaName == "" {
+		return internal.RejectWithMsg("rate-limited: rate limit exceeded")(input)
+	}
+	return internal.RejectWithMsg(fmt.Sprintf("rate-limited: rate limit exceeded (quota: %s)", quotaName))(input)
+}
+
+// WithPenalty makes the rate-limiting sifter impose the given [Penalty] on users who repeatedly exceed the quota.
+//
+// Violations are counted per key derived by the [LimitKey] of the sifter.
+// For sifters that limits per user and kind (e.g. [ByUserAndKind]), violations of quotas for any kinds are counted together,
+// and all events from banned users are rejected regardless of their kinds.
+func (s *SifterUnit) WithPenalty(p *Penalty) *SifterUnit {
+	s.penalty = p
+	return s
+}
+
 // Exclude makes the rate-limiting sifter exclude inputs that match given function from rate-limiting.
 func (s *SifterUnit) Exclude(exclude func(*strfrui.Input) bool) *SifterUnit {
 	s.exclude = exclude
@@ -93,12 +140,11 @@ func (s *SifterUnit) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string)
 	return s
 }
 
-func newSifterUnit(selectLimiter selectRateLimiterFn, deriveLimitKey rateLimitKeyDeriveFn) *SifterUnit {
+func newSifterUnit(selectLimiters selectRateLimitersFn, deriveLimitKey LimitKey) *SifterUnit {
 	return &SifterUnit{
-		selectLimiter:  selectLimiter,
+		selectLimiters: selectLimiters,
 		deriveLimitKey: deriveLimitKey,
 		exclude:        func(i *strfrui.Input) bool { return false },
-		reject:         internal.RejectWithMsg("rate-limited: rate limit exceeded"),
 	}
 }
 
@@ -108,87 +154,222 @@ func newSifterUnit(selectLimiter selectRateLimiterFn, deriveLimitKey rateLimitKe
 //
 // Note that this doesn't impose a rate limit to events not from end-users (i.e. events imported from other relays).
 func ByUser(quota Quota, uk UserKey) *SifterUnit {
+	return ByKey(quota, uk.LimitKey())
+}
+
+// ByKey creates a event-sifter that imposes rate limit on event write request per key derived by the given [LimitKey].
+//
+// For example, you can limit the number of replies to a single thread by passing KeyByTag("e") as key,
+// or limit events per pubkey and source IP address pair by passing CompositeKey(PubKey.LimitKey(), IPAddr.LimitKey()).
+func ByKey(quota Quota, key LimitKey) *SifterUnit {
 	store, _ := memstore.NewCtx(65536)
 	rateLimiter, err := throttled.NewGCRARateLimiterCtx(store, throttled.RateQuota(quota))
 	if err != nil {
-		log.Fatalf("ratelimit.ByUser: failed to initialize rate-limiter: %v", err)
+		log.Fatalf("ratelimit.ByKey: failed to initialize rate-limiter: %v", err)
+	}
+
+	selectLimiters := func(_ *strfrui.Input, key string) []appliedRateLimiter {
+		return []appliedRateLimiter{{rateLimiter: rateLimiter, key: key}}
+	}
+	return newSifterUnit(selectLimiters, key)
+}
+
+// BySourceRelay creates a event-sifter that imposes rate limit on events imported from other relays per source relay.
+//
+// Note that this only imposes a rate limit to events whose source type is [strfrui.SourceTypeStream] or [strfrui.SourceTypeSync].
+// Events from end-users and imported via "strfry import" are always accepted.
+func BySourceRelay(quota Quota) *SifterUnit {
+	return ByKey(quota, KeyBySourceRelay())
+}
+
+// Global creates a event-sifter that imposes a relay-wide rate limit, that is, all events share a single quota regardless of their sources.
+//
+// If you want to exclude some inputs (e.g. events imported via "strfry import") from the global quota, use [SifterUnit.Exclude].
+func Global(quota Quota) *SifterUnit {
+	return ByKey(quota, KeyGlobal())
+}
+
+// ByUserWithQuotaFn creates a event-sifter that imposes rate limit on event write request per user, with quotas that vary by user.
+//
+// quotaFor determines the quota for a user. "Users" are the source IP addresses or the pubkeys of events, depending on the given [UserKey].
+// If quotaFor returns false as ok, no rate limit is imposed on the user.
+// quotaFor should return quotas from a small fixed set. See [ByKeyWithQuotaFn] for details.
+// This is useful to give higher limits to trusted users (e.g. followed pubkeys, NIP-05 verified users or paying members)
+// while keeping stricter limits to unknown ones.
+//
+// Note that this doesn't impose a rate limit to events not from end-users (i.e. events imported from other relays).
+func ByUserWithQuotaFn(quotaFor func(user string) (quota Quota, ok bool), uk UserKey) *SifterUnit {
+	return ByKeyWithQuotaFn(quotaFor, uk.LimitKey())
+}
+
+// max number of distinct quotas tracked by ByKeyWithQuotaFn
+const maxDistinctQuotas = 256
+
+// ByKeyWithQuotaFn creates a event-sifter that imposes rate limit on event write request per key derived by the given [LimitKey],
+// with quotas that vary by key.
+//
+// quotaFor determines the quota for a key. If quotaFor returns false as ok, no rate limit is imposed on the input.
+//
+// quotaFor should return quotas from a small fixed set (e.g. one per tier of users), since a rate-limiter is kept for each distinct quota.
+// Up to 256 distinct quotas are tracked. Beyond that, the least recently used quota is forgotten,
+// and keys limited by it start over with a fresh rate limit the next time.
+func ByKeyWithQuotaFn(quotaFor func(key string) (quota Quota, ok bool), key LimitKey) *SifterUnit {
+	store, _ := memstore.NewCtx(65536)
+
+	// rate-limiters are initialized lazily for each distinct quota.
+	// The number of them is bounded, so that quotas computed from continuous values don't leak memory.
+	type limiterForQuota struct {
+		rateLimiter throttled.RateLimiterCtx
+		id          int
+	}
+	limiters, err := lru.New(maxDistinctQuotas)
+	if err != nil {
+		log.Fatalf("ratelimit.ByKeyWithQuotaFn: failed to initialize rate-limiters: %v", err)
+	}
+	var (
+		mu     sync.Mutex
+		nextID int
+	)
+	getLimiter := func(quota Quota, key string) (appliedRateLimiter, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var l limiterForQuota
+		if v, ok := limiters.Get(quota); ok {
+			l = v.(limiterForQuota)
+		} else {
+			rateLimiter, err := throttled.NewGCRARateLimiterCtx(store, throttled.RateQuota(quota))
+			if err != nil {
+				return appliedRateLimiter{}, err
+			}
+			l = limiterForQuota{rateLimiter: rateLimiter, id: nextID}
+			nextID++
+			limiters.Add(quota, l)
+		}
+		return appliedRateLimiter{
+			rateLimiter: l.rateLimiter,
+			// limiters share the store, so keys must be distinct per limiter
+			key: fmt.Sprintf("%s/%d", key, l.id),
+		}, nil
 	}
 
-	selectLimiter := func(_ *strfrui.Input) throttled.RateLimiterCtx { return rateLimiter }
-	deriveLimitKey := func(input *strfrui.Input) (bool, string) {
-		if !input.SourceType.IsEndUser() {
-			return false, ""
+	selectLimiters := func(_ *strfrui.Input, key string) []appliedRateLimiter {
+		quota, ok := quotaFor(key)
+		if !ok {
+			return nil
 		}
-		switch uk {
-		case IPAddr:
-			if isValidIPAddr(input.SourceInfo) {
-				return true, input.SourceInfo
+		l, err := getLimiter(quota, key)
+		if err != nil {
+			log.Printf("ratelimit.ByKeyWithQuotaFn: failed to initialize rate-limiter for %q: %v", key, err)
+			return nil
+		}
+		return []appliedRateLimiter{l}
+	}
+	return newSifterUnit(selectLimiters, key)
+}
+
+// ByUserTiered creates a event-sifter that imposes rate limit on event write request per user, with quotas defined per tier of users.
+// The quota for each tier is specified by the given list of [QuotaTier]. Each user is applied the quota of the first tier the user belongs to.
+// For users who don't belong to any tier, no rate limit is imposed.
+//
+// "Users" are identified by the source IP address or the pubkey of the event, depending on the given [UserKey].
+//
+// Note that this doesn't impose a rate limit to events not from end-users (i.e. events imported from other relays).
+func ByUserTiered(tiers []QuotaTier, uk UserKey) *SifterUnit {
+	quotaFor := func(user string) (Quota, bool) {
+		for _, tier := range tiers {
+			if tier.matchUser(user) {
+				return tier.quota, true
 			}
-			return false, ""
-		case PubKey:
-			return true, input.Event.PubKey
-		default:
-			return false, ""
 		}
+		return Quota{}, false
 	}
-	return newSifterUnit(selectLimiter, deriveLimitKey)
+	return ByUserWithQuotaFn(quotaFor, uk)
 }
 
 type rateLimiterPerKind struct {
 	matchKind   func(int) bool
+	acrossKinds bool
 	rateLimiter throttled.RateLimiterCtx
+	quotaName   string
 }
 
 // ByUserAndKind creates a event-sifter that imposes rate limit on event write request per user and event kind.
 // The quota for each event kind is specified by the given list of [QuotaForKinds].
 // For event kinds for which a quota is not defined, no rate limit is imposed.
 //
+// Each quota is imposed on each kind separately, unless it is defined by [Quota.ForAllKinds] or [QuotaForKinds.AcrossKinds].
+// If multiple quotas match the kind of an event (e.g. "10 events/min" and "200 events/day" for kind 1), all of them are imposed.
+// The rejection message names the quota that was exceeded (see [QuotaForKinds.Named]).
+//
 // "Users" are identified by the source IP address or the pubkey of the event, depending on the given [UserKey].
 //
 // Note that this doesn't impose a rate limit to events not from end-users (i.e. events imported from other relays).
 func ByUserAndKind(quotas []QuotaForKinds, uk UserKey) *SifterUnit {
+	return ByKeyAndKind(quotas, uk.LimitKey())
+}
+
+// ByKeyAndKind creates a event-sifter that imposes rate limit on event write request per key derived by the given [LimitKey] and event kind.
+// The quota for each event kind is specified by the given list of [QuotaForKinds].
+// For event kinds for which a quota is not defined, no rate limit is imposed.
+//
+// Each quota is imposed on each kind separately, unless it is defined by [Quota.ForAllKinds] or [QuotaForKinds.AcrossKinds].
+// If multiple quotas match the kind of an event, all of them are imposed.
+func ByKeyAndKind(quotas []QuotaForKinds, key LimitKey) *SifterUnit {
 	store, _ := memstore.NewCtx(65536)
 	limiters := make([]rateLimiterPerKind, 0, len(quotas))
-	for _, kq := range quotas {
+	for i, kq := range quotas {
 		rateLimiter, err := throttled.NewGCRARateLimiterCtx(store, throttled.RateQuota(kq.quota))
 		if err != nil {
-			log.Fatalf("ratelimit.ByUser: failed to initialize rate-limiter: %v", err)
+			log.Fatalf("ratelimit.ByKeyAndKind: failed to initialize rate-limiter: %v", err)
+		}
+		name := kq.name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
 		}
 		limiters = append(limiters, rateLimiterPerKind{
 			matchKind:   kq.matchKind,
+			acrossKinds: kq.acrossKinds,
 			rateLimiter: rateLimiter,
+			quotaName:   name,
 		})
 	}
 
-	selectRateLimiter := func(input *strfrui.Input) throttled.RateLimiterCtx {
-		for _, limiter := range limiters {
-			if limiter.matchKind(input.Event.Kind) {
-				return limiter.rateLimiter
-			}
-		}
-		return nil
-	}
-	deriveLimitKey := func(input *strfrui.Input) (bool, string) {
-		if !input.SourceType.IsEndUser() {
-			return false, ""
-		}
+	selectRateLimiters := func(input *strfrui.Input, key string) []appliedRateLimiter {
 		kind := input.Event.Kind
-		switch uk {
-		case IPAddr:
-			if isValidIPAddr(input.SourceInfo) {
-				return true, fmt.Sprintf("%s/%d", input.SourceInfo, kind)
+		applied := make([]appliedRateLimiter, 0, 1)
+		for i, limiter := range limiters {
+			if !limiter.matchKind(kind) {
+				continue
+			}
+			// limiters share the store, so keys must be distinct per limiter
+			limitKey := fmt.Sprintf("%s/%d/%d", key, kind, i)
+			if limiter.acrossKinds {
+				limitKey = fmt.Sprintf("%s/*/%d", key, i)
 			}
-			return false, ""
-		case PubKey:
-			return true, fmt.Sprintf("%s/%d", input.Event.PubKey, kind)
-		default:
-			return false, ""
+			applied = append(applied, appliedRateLimiter{
+				rateLimiter: limiter.rateLimiter,
+				key:         limitKey,
+				quotaName:   limiter.quotaName,
+			})
 		}
+		return applied
 	}
-	return newSifterUnit(selectRateLimiter, deriveLimitKey)
+	return newSifterUnit(selectRateLimiters, key)
 }
 
-func isValidIPAddr(s string) bool {
-	_, err := netip.ParseAddr(s)
-	return err == nil
+// BySourceRelayAndKind creates a event-sifter that imposes rate limit on events imported from other relays per source relay and event kind.
+// The quota for each event kind is specified by the given list of [QuotaForKinds].
+// For event kinds for which a quota is not defined, no rate limit is imposed.
+//
+// Note that this only imposes a rate limit to events whose source type is [strfrui.SourceTypeStream] or [strfrui.SourceTypeSync].
+func BySourceRelayAndKind(quotas []QuotaForKinds) *SifterUnit {
+	return ByKeyAndKind(quotas, KeyBySourceRelay())
+}
+
+// GlobalByKind creates a event-sifter that imposes a relay-wide rate limit per event kind.
+// The quota for each event kind is specified by the given list of [QuotaForKinds].
+// For event kinds for which a quota is not defined, no rate limit is imposed.
+func GlobalByKind(quotas []QuotaForKinds) *SifterUnit {
+	return ByKeyAndKind(quotas, KeyGlobal())
 }
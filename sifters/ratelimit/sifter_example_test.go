@@ -1,6 +1,9 @@
 package ratelimit_test
 
 import (
+	"log"
+	"time"
+
 	"github.com/jiftechnify/strfrui"
 	"github.com/jiftechnify/strfrui/sifters"
 	"github.com/jiftechnify/strfrui/sifters/ratelimit"
@@ -48,3 +51,91 @@ func ExampleByUserAndKind() {
 
 	strfrui.New(limiter).Run()
 }
+
+func ExampleByKey() {
+	// limit replies to a single thread: 100 replies/min per referenced event
+	limiter := ratelimit.ByKey(
+		ratelimit.QuotaPerMin(100),
+		ratelimit.KeyByTag("e"),
+	).
+		Exclude(func(input *strfrui.Input) bool {
+			return input.Event.Kind != 1
+		})
+
+	strfrui.New(limiter).Run()
+}
+
+func ExampleCompositeKey() {
+	// identify "users" by the pair of pubkey and source IP address
+	limiter := ratelimit.ByKey(
+		ratelimit.QuotaPerMin(30),
+		ratelimit.CompositeKey(ratelimit.PubKey.LimitKey(), ratelimit.IPAddr.LimitKey()),
+	)
+
+	strfrui.New(limiter).Run()
+}
+
+func ExampleBySourceRelay() {
+	limiter := sifters.Pipeline(
+		// 1000 events/min per upstream relay (for "strfry stream" / "strfry router" / "strfry sync")
+		ratelimit.BySourceRelay(ratelimit.QuotaPerMin(1000)),
+		// 5000 events/min in total, excluding events imported via "strfry import"
+		ratelimit.Global(ratelimit.QuotaPerMin(5000)).
+			Exclude(func(input *strfrui.Input) bool {
+				return input.SourceType == strfrui.SourceTypeImport
+			}),
+	)
+
+	strfrui.New(limiter).Run()
+}
+
+func ExamplePenalty() {
+	// ban users who exceed the quota 10 times: first for 5 min, then for 1 h, and 24 h after that
+	penalty, err := ratelimit.NewPenalty(10, 5*time.Minute, 1*time.Hour, 24*time.Hour).
+		PersistTo("./penalty.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	limiter := ratelimit.ByUser(
+		ratelimit.QuotaPerMin(30),
+		ratelimit.PubKey,
+	).WithPenalty(penalty)
+
+	strfrui.New(limiter).Run()
+}
+
+func ExampleByUserAndKind_overlappingQuotas() {
+	limiter := ratelimit.ByUserAndKind([]ratelimit.QuotaForKinds{
+		// kind:1 events are limited to 10 events/min AND 200 events/day per user
+		ratelimit.QuotaPerMin(10).ForKinds(1).Named("kind1-per-minute"),
+		ratelimit.QuotaPerDay(200).ForKinds(1).Named("kind1-per-day"),
+		// reactions and reposts are limited to 100 events/h in total per user
+		ratelimit.QuotaPerHour(100).ForKinds(6, 7).AcrossKinds().Named("reactions-and-reposts"),
+		// events of all kinds are limited to 1000 events/day in total per user, in addition to quotas above
+		ratelimit.QuotaPerDay(1000).ForAllKinds(),
+	}, ratelimit.PubKey)
+
+	strfrui.New(limiter).Run()
+}
+
+func ExampleByUserTiered() {
+	var (
+		members   = []string{"member-pubkey1", "member-pubkey2"}
+		followees = map[string]struct{}{"followee-pubkey1": {}}
+	)
+
+	limiter := ratelimit.ByUserTiered([]ratelimit.QuotaTier{
+		// paying members: 1000 events/h
+		ratelimit.QuotaPerHour(1000).WithBurst(100).ForUsers(members...),
+		// followees of the relay admin: 300 events/h
+		ratelimit.QuotaPerHour(300).WithBurst(30).ForUsersMatching(func(pubkey string) bool {
+			_, ok := followees[pubkey]
+			return ok
+		}),
+		// others: 50 events/h
+		ratelimit.QuotaPerHour(50).ForUsersMatching(func(string) bool { return true }),
+	}, ratelimit.PubKey)
+
+	strfrui.New(limiter).Run()
+}
@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -479,3 +480,189 @@ func TestByUserAndKind(t *testing.T) {
 		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddrWithKind("???", 7)))
 	})
 }
+
+func inputFromRelay(st strfrui.SourceType, relayURL string) *strfrui.Input {
+	return &strfrui.Input{
+		SourceType: st,
+		SourceInfo: relayURL,
+		Event:      &nostr.Event{PubKey: "pubkey", Kind: 1},
+	}
+}
+
+func TestBySourceRelay(t *testing.T) {
+	t.Parallel()
+
+	s := BySourceRelay(QuotaPerMin(1))
+
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromRelay(strfrui.SourceTypeStream, "wss://relay1.example.com")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromRelay(strfrui.SourceTypeSync, "wss://relay2.example.com")))
+
+	// quota is shared by events from the same relay, regardless of the source type
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromRelay(strfrui.SourceTypeStream, "wss://relay1.example.com")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromRelay(strfrui.SourceTypeSync, "wss://relay1.example.com/")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromRelay(strfrui.SourceTypeStream, "wss://relay2.example.com")))
+
+	// events from end-users and imported ones are not limited
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.1")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.1")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromRelay(strfrui.SourceTypeImport, "")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromRelay(strfrui.SourceTypeImport, "")))
+}
+
+func TestGlobal(t *testing.T) {
+	t.Parallel()
+
+	s := Global(QuotaPerMin(1).WithBurst(2))
+
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.1")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromRelay(strfrui.SourceTypeStream, "wss://relay1.example.com")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromRelay(strfrui.SourceTypeImport, "")))
+
+	// all sources share the same quota
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromIPAddr("192.168.1.2")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromRelay(strfrui.SourceTypeSync, "wss://relay2.example.com")))
+}
+
+func expectRejectWithMsg(t *testing.T, wantMsg string) func(got *strfrui.Result, err error) {
+	return func(got *strfrui.Result, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Action != strfrui.ActionReject || got.Msg != wantMsg {
+			t.Fatalf("want: reject with msg %q, got: %v", wantMsg, got)
+		}
+	}
+}
+
+func TestByUserAndKind_OverlappingQuotas(t *testing.T) {
+	t.Parallel()
+
+	quotas := []QuotaForKinds{
+		QuotaPerHour(1).WithBurst(1).ForKinds(1),
+		QuotaPerHour(1).WithBurst(2).ForKindsMatching(func(kind int) bool { return kind < 10000 }).Named("regular"),
+	}
+	s := ByUserAndKind(quotas, PubKey)
+
+	// kind 1: both quotas are imposed, and the stricter one is exceeded first
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+	expectRejectWithMsg(t, "rate-limited: rate limit exceeded (quota: kinds [1])")(s.Sift(inputFromPubkeyWithKind("1", 1)))
+
+	// kind 7: only the quota for regular kinds is imposed, separately from kind 1
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+	expectRejectWithMsg(t, "rate-limited: rate limit exceeded (quota: regular)")(s.Sift(inputFromPubkeyWithKind("1", 7)))
+}
+
+func TestByUserAndKind_QuotasAcrossKinds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ForAllKinds imposes the quota on all kinds in total", func(t *testing.T) {
+		t.Parallel()
+
+		quotas := []QuotaForKinds{
+			QuotaPerHour(1).WithBurst(2).ForKinds(1),
+			QuotaPerHour(1).WithBurst(3).ForAllKinds(),
+		}
+		s := ByUserAndKind(quotas, PubKey)
+
+		// rotating kinds doesn't evade the catch-all quota
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 30023)))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+		expectRejectWithMsg(t, "rate-limited: rate limit exceeded (quota: all kinds)")(s.Sift(inputFromPubkeyWithKind("1", 6)))
+		expectRejectWithMsg(t, "rate-limited: rate limit exceeded (quota: all kinds)")(s.Sift(inputFromPubkeyWithKind("1", 7)))
+
+		// the quota is imposed per user
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("2", 7)))
+	})
+
+	t.Run("AcrossKinds imposes the quota on the matching kinds in total", func(t *testing.T) {
+		t.Parallel()
+
+		quotas := []QuotaForKinds{
+			QuotaPerHour(1).WithBurst(1).ForKinds(6, 7).AcrossKinds(),
+		}
+		s := ByUserAndKind(quotas, PubKey)
+
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 6)))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 7)))
+		expectRejectWithMsg(t, "rate-limited: rate limit exceeded (quota: kinds [6 7])")(s.Sift(inputFromPubkeyWithKind("1", 6)))
+
+		// kinds not matching the quota are not limited
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkeyWithKind("1", 1)))
+	})
+}
+
+func TestByUserTiered(t *testing.T) {
+	t.Parallel()
+
+	tiers := []QuotaTier{
+		QuotaPerHour(1).WithBurst(2).ForUsers("trusted"),
+		QuotaPerHour(1).ForUsersMatching(func(user string) bool { return user != "admin" }),
+	}
+	s := ByUserTiered(tiers, PubKey)
+
+	// trusted user gets higher limit
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("trusted")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("trusted")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("trusted")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("trusted")))
+
+	// unknown users get stricter limit
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("unknown")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey("unknown")))
+
+	// users who don't belong to any tier are not limited
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("admin")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("admin")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("admin")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey("admin")))
+}
+
+func TestByUserWithQuotaFn(t *testing.T) {
+	t.Parallel()
+
+	quotaFor := func(user string) (Quota, bool) {
+		switch user {
+		case "192.168.1.1":
+			return QuotaPerHour(1).WithBurst(1), true
+		case "192.168.1.2":
+			return QuotaPerHour(1), true
+		default:
+			return Quota{}, false
+		}
+	}
+	s := ByUserWithQuotaFn(quotaFor, IPAddr)
+
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.1")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.1")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromIPAddr("192.168.1.1")))
+
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.2")))
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromIPAddr("192.168.1.2")))
+
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.3")))
+	expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromIPAddr("192.168.1.3")))
+}
+
+func TestByUserWithQuotaFn_ManyDistinctQuotas(t *testing.T) {
+	t.Parallel()
+
+	// each user gets a distinct quota
+	quotaFor := func(user string) (Quota, bool) {
+		n, _ := strconv.Atoi(user)
+		return QuotaPerDuration(1, time.Hour+time.Duration(n)*time.Second), true
+	}
+	s := ByUserWithQuotaFn(quotaFor, PubKey)
+
+	for i := 0; i < 2*maxDistinctQuotas; i++ {
+		expectResult(t, strfrui.ActionAccept)(s.Sift(inputFromPubkey(strconv.Itoa(i))))
+	}
+	// recently used quotas are still imposed
+	expectResult(t, strfrui.ActionReject)(s.Sift(inputFromPubkey(strconv.Itoa(2*maxDistinctQuotas - 1))))
+}
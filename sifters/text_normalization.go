@@ -0,0 +1,92 @@
+package sifters
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextNormalization describes how texts are normalized before matching, in order to prevent spammers from evading filters
+// with tricks like capitals, full-width letters, homoglyphs or zero-width characters.
+//
+// Normalizations are applied in the following order: StripInvisible, NFKC, FoldConfusables and CaseFold.
+type TextNormalization struct {
+	// Removes invisible characters, such as zero-width spaces/joiners, bidi control characters and variation selectors.
+	StripInvisible bool
+
+	// Applies Unicode normalization form NFKC, which unifies compatibility characters such as full-width letters ("ｎｏｓｔｒ" → "nostr")
+	// and mathematical alphanumeric symbols ("𝐧𝐨𝐬𝐭𝐫" → "nostr").
+	NFKC bool
+
+	// Replaces Cyrillic and Greek letters that look like Latin letters (homoglyphs) with the corresponding Latin letters (e.g. Cyrillic "о" → Latin "o").
+	// Note that it also affects legitimate texts in those scripts.
+	FoldConfusables bool
+
+	// Applies Unicode case folding, so that matching is case-insensitive.
+	CaseFold bool
+}
+
+// FullNormalization returns a [TextNormalization] with all normalizations enabled.
+func FullNormalization() TextNormalization {
+	return TextNormalization{
+		StripInvisible:  true,
+		CaseFold:        true,
+		NFKC:            true,
+		FoldConfusables: true,
+	}
+}
+
+func (n TextNormalization) normalize(s string) string {
+	if n.StripInvisible {
+		s = strings.Map(func(r rune) rune {
+			if isInvisible(r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	if n.NFKC {
+		s = norm.NFKC.String(s)
+	}
+	// fold confusables before case folding, since lowercase forms of some capitals look different (e.g. Greek "Ν" → "ν")
+	if n.FoldConfusables {
+		s = strings.Map(func(r rune) rune {
+			if l, ok := confusables[r]; ok {
+				return l
+			}
+			return r
+		}, s)
+	}
+	if n.CaseFold {
+		// Caser is stateful, so create one for each call to make it safe for concurrent use
+		s = cases.Fold().String(s)
+	}
+	return s
+}
+
+func isInvisible(r rune) bool {
+	switch r {
+	case '\u034f', // combining grapheme joiner
+		'\u115f', '\u1160', '\u3164', '\uffa0': // hangul fillers
+		return true
+	}
+	return unicode.In(r, unicode.Cf, unicode.Variation_Selector)
+}
+
+// confusables maps Cyrillic and Greek letters to Latin letters that look (almost) the same.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x',
+	'і': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l', 'ү': 'y',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'У': 'Y', 'Х': 'X',
+	'І': 'I', 'Ј': 'J', 'Ѕ': 'S', 'Ԁ': 'D', 'Ԛ': 'Q', 'Ԝ': 'W', 'Һ': 'H', 'Ӏ': 'I', 'Ү': 'Y',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ζ': 'z', 'η': 'n', 'ι': 'i', 'κ': 'k', 'μ': 'u', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't',
+	'υ': 'u', 'χ': 'x',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T',
+	'Υ': 'Y', 'Χ': 'X',
+	// Latin letters that look like other Latin letters
+	'ı': 'i', 'ɑ': 'a', 'ɡ': 'g', 'ɩ': 'i', 'ʏ': 'y',
+}
@@ -0,0 +1,30 @@
+package sifters
+
+import "testing"
+
+func TestTextNormalization(t *testing.T) {
+	tests := []struct {
+		name string
+		n    TextNormalization
+		in   string
+		want string
+	}{
+		{"no normalization", TextNormalization{}, "Ｎo\u200bstr", "Ｎo\u200bstr"},
+		{"strip invisible", TextNormalization{StripInvisible: true}, "n\u200bo\u200ds\u2060t\ufeffr\ufe0f", "nostr"},
+		{"NFKC", TextNormalization{NFKC: true}, "ｎｏｓｔｒ 𝐙𝐀𝐏", "nostr ZAP"},
+		{"case fold", TextNormalization{CaseFold: true}, "NoStR Straße", "nostr strasse"},
+		{"fold confusables", TextNormalization{FoldConfusables: true}, "nоstr ΖΑΡ", "nostr ZAP"}, // Cyrillic "о", Greek "ΖΑΡ"
+		{"NFKC then case fold", TextNormalization{NFKC: true, CaseFold: true}, "𝐍𝐎𝐒𝐓𝐑", "nostr"},
+		{"full", FullNormalization(), "Ｎ\u200bОЅТＲ", "nostr"}, // Cyrillic "ОЅТ"
+		{"full with Greek capitals", FullNormalization(), "ΜΑΧ ΝOSTR ΥΕΤΙ ΖΗΚ", "max nostr yeti zhk"},
+		{"full with Greek small letters", FullNormalization(), "ταχ ζηv", "tax znv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.normalize(tt.in); got != tt.want {
+				t.Fatalf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package sifters
 
 import (
+	"errors"
 	"log"
+	"unicode/utf8"
 
 	"github.com/jiftechnify/strfrui"
 	"github.com/jiftechnify/strfrui/sifters/internal"
@@ -28,14 +30,22 @@ type inputMatcher func(*strfrui.Input) (inputMatchResult, error)
 //
 // This type is exposed only for document organization purpose. You shouldn't initialize this struct directly.
 type SifterUnit struct {
-	match  inputMatcher
-	mode   Mode
-	reject internal.RejectionFn
+	match            inputMatcher
+	mode             Mode
+	reject           internal.RejectionFn
+	rejectCustomized bool
 }
 
 func (s *SifterUnit) Sift(input *strfrui.Input) (*strfrui.Result, error) {
 	matched, err := s.match(input)
 	if err != nil {
+		var rej *rejection
+		if errors.As(err, &rej) {
+			if s.rejectCustomized {
+				return s.reject(input), nil
+			}
+			return internal.RejectWithMsg(rej.msg)(input), nil
+		}
 		return nil, err
 	}
 	if shouldAccept(matched, s.mode) {
@@ -48,18 +58,21 @@ func (s *SifterUnit) Sift(input *strfrui.Input) (*strfrui.Result, error) {
 // which pretend to accept the input but actually reject it.
 func (s *SifterUnit) ShadowReject() *SifterUnit {
 	s.reject = internal.ShadowReject
+	s.rejectCustomized = true
 	return s
 }
 
 // RejectWithMsg makes the sifter reject the input with the given message.
 func (s *SifterUnit) RejectWithMsg(msg string) *SifterUnit {
 	s.reject = internal.RejectWithMsg(msg)
+	s.rejectCustomized = true
 	return s
 }
 
 // RejectWithMsgFromInput makes the sifter reject the input with the message derived from the input by the given function.
 func (s *SifterUnit) RejectWithMsgFromInput(getMsg func(*strfrui.Input) string) *SifterUnit {
 	s.reject = internal.RejectWithMsgFromInput(getMsg)
+	s.rejectCustomized = true
 	return s
 }
 
@@ -80,6 +93,36 @@ const (
 	inputAlwaysReject
 )
 
+// rejection is an error that inputMatchers can return to make the sifter reject the input with a message specific to the input.
+// If the rejection behavior of the sifter is customized, the message is ignored.
+type rejection struct {
+	msg string
+}
+
+func (r *rejection) Error() string {
+	return r.msg
+}
+
+// rejectWithMsg makes an inputMatcher reject the input with the given message.
+func rejectWithMsg(msg string) (inputMatchResult, error) {
+	return inputAlwaysReject, &rejection{msg: msg}
+}
+
+// max length of values taken from events and echoed in rejection messages, in bytes
+const maxEchoedValueLen = 64
+
+// truncateForMsg truncates a value taken from an event, so that a rejection message echoing it doesn't get too long.
+func truncateForMsg(s string) string {
+	if len(s) <= maxEchoedValueLen {
+		return s
+	}
+	cut := maxEchoedValueLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
 func matchResultFromBool(b bool, err error) (inputMatchResult, error) {
 	if err != nil {
 		return inputAlwaysReject, err
@@ -1,6 +1,9 @@
 package sifters
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestShouldAccept(t *testing.T) {
 	tests := []struct {
@@ -24,3 +27,21 @@ func TestShouldAccept(t *testing.T) {
 		}
 	}
 }
+
+func TestTruncateForMsg(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"short", "short"},
+		{strings.Repeat("a", 64), strings.Repeat("a", 64)},
+		{strings.Repeat("a", 65), strings.Repeat("a", 64) + "..."},
+		// never cut in the middle of a multibyte character
+		{"a" + strings.Repeat("あ", 30), "a" + strings.Repeat("あ", 21) + "..."},
+	}
+	for _, tt := range tests {
+		if got := truncateForMsg(tt.in); got != tt.want {
+			t.Errorf("truncateForMsg(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -123,11 +123,13 @@ func (i *Input) ShadowReject() (*Result, error) {
 
 // "Machine-readable prefixes" for rejection messages. Use them with [BuildRejectMessage].
 const (
-	RejectReasonPrefixBlocked     = "blocked"
-	RejectReasonPrefixRateLimited = "rate-limited"
-	RejectReasonPrefixInvalid     = "invalid"
-	RejectReasonPrefixPoW         = "pow"
-	RejectReasonPrefixError       = "error"
+	RejectReasonPrefixBlocked      = "blocked"
+	RejectReasonPrefixRateLimited  = "rate-limited"
+	RejectReasonPrefixInvalid      = "invalid"
+	RejectReasonPrefixPoW          = "pow"
+	RejectReasonPrefixError        = "error"
+	RejectReasonPrefixAuthRequired = "auth-required"
+	RejectReasonPrefixRestricted   = "restricted"
 )
 
 // BuildRejectMessage builds a rejection message with a machine-readable prefix.